@@ -1,6 +1,7 @@
 package sedoc
 
 import (
+	stdcontext "context"
 	"fmt"
 	"time"
 )
@@ -12,11 +13,25 @@ type API struct {
 	ResponseFormat  Arguments              `json:"response_format,omitempty" xml:"response_format,omitempty"  yaml:"response_format,omitempty"`
 	Commands        Commands               `json:"commands,omitempty" xml:"commands,omitempty" yaml:"commands,omitempty"`
 	Errors          Errors                 `json:"errors,omitempty" xml:"errors,omitempty" yaml:"errors,omitempty"`
+	ErrorRegistry   *ErrorRegistry         `json:"error_namespaces,omitempty" xml:"error_namespaces,omitempty" yaml:"error_namespaces,omitempty"`
+	Codecs          *Codecs                `json:"codecs,omitempty" xml:"codecs,omitempty" yaml:"codecs,omitempty"`
+	Types           *ArgumentTypes         `json:"types,omitempty" xml:"types,omitempty" yaml:"types,omitempty"`
+	Server          string                 `json:"server,omitempty" xml:"server,attr,omitempty" yaml:"server,omitempty"`
+	Version         string                 `json:"version,omitempty" xml:"version,attr,omitempty" yaml:"version,omitempty"`
+	EnableMeta      bool                   `json:"-" xml:"-" yaml:"-"`
+	Debug           bool                   `json:"-" xml:"-" yaml:"-"`
+	Jobs            *JobManager            `json:"-" xml:"-" yaml:"-"`
+	Concurrency     *ConcurrencyLimit      `json:"-" xml:"-" yaml:"-"`
+	Tracer          Tracer                 `json:"-" xml:"-" yaml:"-"`
+	Catalogs        *Catalogs              `json:"-" xml:"-" yaml:"-"`
 	PrefixArguments string                 `json:"-" xml:"-" yaml:"-"`
 	PrefixSet       string                 `json:"-" xml:"-" yaml:"-"`
 	PrefixWhere     string                 `json:"-" xml:"-" yaml:"-"`
 	ErrorHandler    CustomErrorHandlerFunc `json:"-" xml:"-" yaml:"-"`
 	middleware      []MiddlewareFunc
+	premiddleware   []MiddlewareFunc
+	subscriptions   *subscriptions
+	limiters        *limiters
 }
 
 // HandlerFunc func
@@ -51,34 +66,64 @@ func New() (api *API) {
 		Required:    true,
 		Description: "Command name string",
 	}
+	subscription := Argument{
+		Name:        "subscription",
+		Type:        ArgumentTypeString,
+		Description: "Subscription identifier of subscription command event (generated by server)",
+	}
 	args := Argument{
 		Name:        "args",
-		Type:        argumentTypeObject,
+		Type:        ArgumentTypeObject,
 		Description: "Extra request parameters, one-level object",
 	}
 	where := Argument{
 		Name:        "where",
-		Type:        argumentTypeArray,
-		Description: "Search item(s) parameters, simple Array of one-level objects",
+		Type:        ArgumentTypeArray,
+		Description: "Search item(s) parameters, Array (joined by OR) of one-level objects (conditions joined by AND). Condition operator may be set by key suffix like `created[gt]` or by nested object like `{\"created\": {\"gt\": ...}}`",
 	}
 	set := Argument{
 		Name:        "set",
-		Type:        argumentTypeObject,
+		Type:        ArgumentTypeObject,
 		Description: "Item(s) data to set, one-level object",
 	}
 	result := Argument{
 		Name:        "result",
-		Type:        argumentTypeObject,
-		Description: "Result object. For XML maybe used another name",
+		Type:        ArgumentTypeObject,
+		Description: "Result object, described by command `result` schema. For XML maybe used another name",
+	}
+	page := Argument{
+		Name:        "page",
+		Type:        ArgumentTypeObject,
+		Description: "Pagination info of list commands. Contains `count`, `offset`, `total` and `next_cursor` fields",
+	}
+	meta := Argument{
+		Name:        "meta",
+		Type:        ArgumentTypeObject,
+		Description: "Response metadata. Contains `duration`, `server`, `version` and `warnings` fields",
+	}
+	trace := Argument{
+		Name:        "trace",
+		Type:        ArgumentTypeString,
+		Description: "W3C trace context `traceparent` value, formatted like \"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01\"",
+	}
+	locale := Argument{
+		Name:        "locale",
+		Type:        ArgumentTypeString,
+		Description: "Locale of error and help descriptions, like \"de-AT\" or Accept-Language header value",
 	}
 	errorArg := Argument{
 		Name:        "error",
-		Type:        argumentTypeObject,
-		Description: "Error object. Contains `code` and `desc` fields",
+		Type:        ArgumentTypeObject,
+		Description: "Error object. Contains `code` and `desc` fields, optional `retry_after` and `details` (list of `field`, `reason`, `expected` and `actual`)",
 	}
 	api = &API{
-		Errors:   DefaultErrors,
-		Commands: Commands{},
+		Errors:        DefaultErrors,
+		Commands:      Commands{},
+		Types:         NewArgumentTypes(DefaultArgumentTypes...),
+		ErrorRegistry: NewErrorRegistry(),
+		Codecs:        NewCodecs(DefaultCodecs...),
+		subscriptions: &subscriptions{active: map[string]activeSubscription{}},
+		limiters:      &limiters{commands: map[string]*limiter{}},
 		RequestFormat: Arguments{
 			id,
 			datetime,
@@ -87,24 +132,33 @@ func New() (api *API) {
 			args,
 			where,
 			set,
+			trace,
+			locale,
 		},
 		ResponseFormat: Arguments{
 			id,
 			datetime,
 			session,
 			command,
+			subscription,
 			args,
 			result,
+			page,
+			meta,
 			errorArg,
 		},
 		PrefixArguments: "",
 		PrefixSet:       "2-",
 		PrefixWhere:     "4-",
 	}
+	if err := api.ErrorRegistry.Mount(DefaultErrorNamespace()); err != nil {
+		// builtin namespace is broken (e.g. DefaultErrors code without name)
+		panic(err)
+	}
 	api.ErrorHandler = func(err error, c Context) {
 		if err != nil {
 			var ok bool
-			c.Response().Error, ok = err.(*Error)
+			c.Response().Error, ok = asError(err)
 			if !ok {
 				serr := DefaultErrors.Get(ErrUnknown)
 				c.Response().Error = &serr
@@ -121,18 +175,14 @@ func New() (api *API) {
 				command := &api.Commands[cidx]
 				for eidx := range command.Examples {
 					example := &command.Examples[eidx]
-					example.Request.JSON = example.Request.JSONString()
-					example.Request.XML = example.Request.XMLString()
-					example.Request.YAML = example.Request.YAMLString()
+					example.Request.Encodings = api.Codecs.Render(example.Request.Object)
 					for ridx := range example.Responses {
 						response := &example.Responses[ridx]
-						response.JSON = response.JSONString()
-						response.XML = response.XMLString()
-						response.YAML = response.YAMLString()
+						response.Encodings = api.Codecs.Render(response.Object)
 					}
 				}
 			}
-			c.Response().Result = api
+			c.Response().Result = api.Localized(c.Request().Locale)
 			return nil
 		},
 		Examples: []Example{
@@ -159,6 +209,12 @@ func (api *API) Use(middleware ...MiddlewareFunc) {
 	api.middleware = append(api.middleware, middleware...)
 }
 
+// Pre add middleware executed before request validation
+// (Context Command may be invalid)
+func (api *API) Pre(middleware ...MiddlewareFunc) {
+	api.premiddleware = append(api.premiddleware, middleware...)
+}
+
 // GetCommand return Command
 func (api *API) GetCommand(name string) Command {
 	if cmd, err := api.Commands.Get(name); err == nil {
@@ -167,46 +223,143 @@ func (api *API) GetCommand(name string) Command {
 	return Command{}
 }
 
-// AddCommand append handler with help to API.
-// AddCommand will rewrite command with the same name
+// AddCommand append handler with help to API. Command Errors are
+// completed by errors implied by its arguments, Kind and limits.
+// AddCommand will rewrite command with the same name. AddCommand panics if
+// Command RateLimit is invalid
 func (api *API) AddCommand(cmd Command) {
+	cmd.Arguments = cmd.withListArguments()
+	cmd.Errors = cmd.withImpliedErrors()
+	if cmd.Kind == CommandKindSubscription && !api.Commands.Contains(CommandUnsubscribe) {
+		api.AddCommand(api.unsubscribeCommand())
+	}
+	if cmd.Kind == CommandKindAsync && !api.Commands.Contains(CommandJobStatus) {
+		if api.Jobs == nil {
+			api.Jobs = NewJobManager(NewMemoryJobStore(), 0)
+		}
+		for _, jc := range api.jobCommands() {
+			api.AddCommand(jc)
+		}
+	}
 	api.RemoveCommand(cmd.Name)
-	_ = api.Commands.Add(cmd)
+	if err := api.Commands.Append(cmd); err != nil {
+		panic(err.Error())
+	}
 }
 
 // RemoveCommand remove command from API
 func (api *API) RemoveCommand(name string) {
-	if _, err := api.Commands.Get(name); err != nil {
-		_ = api.Commands.Remove(name)
+	_ = api.Commands.Remove(name)
+	if api.limiters != nil {
+		api.limiters.remove(name)
 	}
 }
 
 // Execute command from API
 func (api *API) Execute(request *Request) *Response {
-	c := &context{api: api, req: request}
-	var err error
-	if c.req == nil {
-		err = api.NewError(ErrInvalidRequest)
+	return api.ExecuteContext(stdcontext.Background(), request)
+}
+
+// ExecuteContext execute command from API with context of execution
+func (api *API) ExecuteContext(ctx stdcontext.Context, request *Request) *Response {
+	c := &context{ctx: ctx, api: api, req: request}
+	api.execute(c)
+	return c.Response()
+}
+
+func (api *API) execute(c *context) {
+	start := time.Now()
+	invalid := c.req == nil
+	defer api.startSpan(c)()
+	handler := api.wrap(c, "sedoc.process", func(Context) error {
+		return api.process(c, invalid)
+	}, api.premiddleware...)
+	api.handleError(c, api.protect(c, handler))
+	fillResponseMissingDataFromRequest(c.Request(), c.Response())
+	c.Response().Arguments = c.Command().redact(c.Response().Arguments, c.Command().Arguments)
+	api.localizeError(c)
+	api.fillMeta(c.Response(), start)
+	if c.stream != nil {
+		c.endStream()
+	}
+}
+
+// process validate request and run Command by its Kind
+func (api *API) process(c *context, invalid bool) (err error) {
+	if invalid {
+		return api.NewError(ErrInvalidRequest)
 	} else if !c.Command().Valid() {
-		err = api.NewError(ErrUnknownCommand)
-	} else {
-		err = c.checkRequestArguments()
-	}
-	if err == nil {
-		handler := c.Command().Handler
-		for idx := len(api.middleware) - 1; idx >= 0; idx-- {
-			handler = api.middleware[idx](handler)
-		}
-		err = handler(c)
+		return api.NewError(ErrUnknownCommand)
+	} else if err = c.checkRequestArguments(); err != nil {
+		return
+	}
+	for _, warning := range c.Command().deprecationWarnings(c.Request()) {
+		c.Warn("%s", warning)
 	}
+	release, err := api.acquire(c)
 	if err != nil {
-		serr, ok := err.(*Error)
-		if !ok {
-			serr = api.NewError(ErrUnknown)
-			serr.Description = fmt.Sprintf("%s: %s", serr.Description, err.Error())
+		return
+	}
+	defer release()
+	switch c.Command().Kind {
+	case CommandKindSubscription:
+		var done func()
+		if done, err = api.subscribe(c); err == nil {
+			defer done()
+			err = api.run(c)
 		}
-		c.api.ErrorHandler(serr, c)
+	case CommandKindAsync:
+		// middleware (idempotency, rate limits etc) is applied to submission
+		err = api.wrap(c, "sedoc.submit", func(Context) error {
+			return api.submitJob(c)
+		}, api.middleware...)(c)
+	default:
+		err = api.run(c)
 	}
-	fillResponseMissingDataFromRequest(c.Request(), c.Response())
-	return c.Response()
+	return
+}
+
+// protect call handler with c. Panic (of Pre middleware, validation or
+// Command Handler) is recovered into ErrUnknown with PanicError Internal
+// and is re-panicked after ErrorHandler in Debug mode
+func (api *API) protect(c *context, handler HandlerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := recoveredPanic(r)
+			err = api.NewErrorInternal(ErrUnknown, perr, "panic")
+			if api.Debug {
+				api.handleError(c, err)
+				panic(perr.Value)
+			}
+		}
+	}()
+	return handler(c)
+}
+
+// run Command Handler of validated request wrapped by middleware
+func (api *API) run(c *context) (err error) {
+	handler := api.wrap(c, "sedoc.handler", c.Command().Handler, api.middleware...)
+	if err = handler(c); err == nil {
+		err = c.applyPage()
+	}
+	if err == nil && c.stream != nil {
+		err = c.streamResult()
+	}
+	return
+}
+
+func (api *API) handleError(c *context, err error) {
+	if err != nil {
+		c.api.ErrorHandler(api.toError(err), c)
+	}
+}
+
+// toError convert err into *Error (ErrUnknown if err does not wrap *Error)
+func (api *API) toError(err error) *Error {
+	serr, ok := asError(err)
+	if !ok {
+		serr = api.NewError(ErrUnknown)
+		serr.Description = fmt.Sprintf("%s: %s", serr.Description, err.Error())
+	}
+	return serr
 }
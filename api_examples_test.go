@@ -264,3 +264,50 @@ func ExampleErrors_custom() {
 	})
 	// ...
 }
+
+// Use tagged struct to declare command arguments and Bind them in handler
+func ExampleContext_bind() {
+	// ...
+	type userGet struct {
+		ID     int64 `sedoc:"id,where,required" desc:"Identifier"`
+		Count  int   `sedoc:"count" desc:"Count of items"`
+		Offset int   `sedoc:"offset" desc:"Items offset"`
+	}
+	cmd := sedoc.Command{
+		Name:        "user.get",
+		Description: "Get existed single user or user list.",
+		Handler: func(c sedoc.Context) error {
+			in := userGet{}
+			if err := c.Bind(&in); err != nil {
+				return err
+			}
+			// ...
+			return nil
+		},
+	}
+	if err := cmd.ArgumentsOf(userGet{}); err != nil {
+		log.Fatal(err)
+	}
+	api.AddCommand(cmd)
+	// ...
+}
+
+// Use TypedCommand to declare command by typed handler
+func ExampleTypedCommand() {
+	// ...
+	type signinIn struct {
+		Login    string `sedoc:"login,where,required" desc:"Login string"`
+		Password string `sedoc:"password,where,required" desc:"Password string"`
+	}
+	type signinOut struct {
+		Session string `json:"session" desc:"Session token uuid"`
+	}
+	api.AddCommand(sedoc.TypedCommand(sedoc.Command{
+		Name:        "signin",
+		Description: "Sign in (login). Create new session.",
+	}, func(c sedoc.Context, in signinIn) (*signinOut, error) {
+		// ...
+		return &signinOut{}, nil
+	}))
+	// ...
+}
@@ -0,0 +1,103 @@
+package sedoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newPanicAPI() *API {
+	a := New()
+	a.AddCommand(Command{
+		Name:    "handler.panic",
+		Handler: func(c Context) error { panic("boom") },
+	})
+	a.AddCommand(Command{
+		Name:    "middleware.panic",
+		Handler: func(c Context) error { return nil },
+	})
+	a.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if c.Request().Command == "middleware.panic" {
+				var m map[string]int
+				m["nil"]++
+			}
+			return next(c)
+		}
+	})
+	a.Pre(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if c.Request().Command == "pre.panic" {
+				panic("pre boom")
+			}
+			return next(c)
+		}
+	})
+	return a
+}
+
+func TestAPI_Execute_panic(t *testing.T) {
+	a := newPanicAPI()
+	var handled error
+	handler := a.ErrorHandler
+	a.ErrorHandler = func(err error, c Context) {
+		handled = err
+		handler(err, c)
+	}
+	for _, tt := range []struct{ command, value string }{
+		{"handler.panic", "boom"},
+		{"middleware.panic", "assignment to entry in nil map"},
+		{"pre.panic", "pre boom"},
+	} {
+		t.Run(tt.command, func(t *testing.T) {
+			resp := a.Execute(&Request{Command: tt.command})
+			if resp.Error == nil || resp.Error.Code != ErrUnknown || resp.Error != handled {
+				t.Fatalf("Execute() error = %v, handled %v", resp.Error, handled)
+			}
+			var perr *PanicError
+			if !errors.As(resp.Error.Internal, &perr) {
+				t.Fatalf("Error.Internal = %T, want *PanicError", resp.Error.Internal)
+			}
+			if !strings.Contains(perr.Error(), tt.value) || !strings.Contains(string(perr.Stack), "api_test.go") {
+				t.Errorf("PanicError = %v", perr)
+			}
+			if strings.Contains(resp.Error.Description, tt.value) {
+				t.Errorf("Error.Description = %q exposes panic value", resp.Error.Description)
+			}
+		})
+	}
+}
+
+func TestAPI_Execute_panicDebug(t *testing.T) {
+	a := newPanicAPI()
+	a.Debug = true
+	var handled error
+	a.ErrorHandler = func(err error, c Context) { handled = err }
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recover() = %v, want boom", r)
+		}
+		if handled == nil {
+			t.Error("panic is not reported to ErrorHandler")
+		}
+	}()
+	a.Execute(&Request{Command: "handler.panic"})
+}
+
+func TestAPI_AddCommand_replace(t *testing.T) {
+	a := New()
+	a.AddCommand(Command{Name: "foo", Description: "first", Handler: func(c Context) error { return nil }})
+	a.AddCommand(Command{Name: "foo", Description: "second", Handler: func(c Context) error { return nil }})
+	if cmd := a.GetCommand("foo"); cmd.Description != "second" {
+		t.Errorf("GetCommand() = %v, want replaced command", cmd)
+	}
+	if err := a.Commands.Append(Command{Name: "foo"}); err == nil {
+		t.Error("Commands.Append() of existing command succeeded")
+	}
+	if err := a.Errors.Append(Error{Code: ErrUnknown}); err == nil {
+		t.Error("Errors.Append() of existing error succeeded")
+	}
+	if err := a.Errors.Append(Error{Code: LastUsedErrorCode + 1, Description: "custom"}); err != nil || a.NewError(LastUsedErrorCode+1).Description != "custom" {
+		t.Errorf("Errors.Append() error = %v", err)
+	}
+}
@@ -27,6 +27,24 @@ func Nullable(arg sedoc.Argument) sedoc.Argument {
 	return arg
 }
 
+// Deprecated func return copy of arg with Deprecated field setted to reason
+func Deprecated(arg sedoc.Argument, reason string) sedoc.Argument {
+	arg.Deprecated = reason
+	return arg
+}
+
+// Sensitive func return copy of arg with Sensitive field setted to true
+func Sensitive(arg sedoc.Argument) sedoc.Argument {
+	arg.Sensitive = true
+	return arg
+}
+
+// Operators func return copy of arg with Operators field setted to ops
+func Operators(arg sedoc.Argument, ops ...sedoc.Operator) sedoc.Argument {
+	arg.Operators = append(sedoc.Operators{}, ops...)
+	return arg
+}
+
 var (
 	// ID is integer identifier argument
 	ID = sedoc.Argument{Name: "id", Type: sedoc.ArgumentTypeInteger, Description: "Identifier", RegExp: RegexpInteger}
@@ -35,35 +53,64 @@ var (
 	// Login argument
 	Login = sedoc.Argument{Name: "login", Type: sedoc.ArgumentTypeString, Description: "Login string"}
 	// Password argument
-	Password = sedoc.Argument{Name: "password", Type: sedoc.ArgumentTypeString, Description: "Password string"}
+	Password = sedoc.Argument{Name: "password", Type: sedoc.ArgumentTypeString, Description: "Password string", Sensitive: true}
 	// Surname argument
 	Surname = sedoc.Argument{Name: "surname", Type: sedoc.ArgumentTypeString, Description: "Surname string"}
 	// Name argument
 	Name = sedoc.Argument{Name: "name", Type: sedoc.ArgumentTypeString, Description: "Name string"}
 	// Patronymic argument
 	Patronymic = sedoc.Argument{Name: "patronymic", Type: sedoc.ArgumentTypeString, Description: "Patronymic string"}
-	// Email argument
-	Email = sedoc.Argument{Name: "email", Type: sedoc.ArgumentTypeString, Description: "Email string", RegExp: RegexpEmail}
+	// Email argument. Its value is parsed as types.Email (not string); copy it
+	// with Type set to sedoc.ArgumentTypeString to keep a plain string value
+	Email = sedoc.Argument{Name: "email", Type: sedoc.ArgumentTypeEmail, Description: "Email address"}
 	// EmailNull argument
-	EmailNull = sedoc.Argument{Name: "email_null", Type: sedoc.ArgumentTypeBoolean, Description: ""}
+	//
+	// Deprecated: use Operators(Nullable(Email), sedoc.OperatorEq, sedoc.OperatorNull) and "email[null]" condition
+	EmailNull = sedoc.Argument{Name: "email_null", Type: sedoc.ArgumentTypeBoolean, Description: "", Deprecated: `use "email[null]" condition`}
 	// EmailNotNull argument
-	EmailNotNull = sedoc.Argument{Name: "email_not_null", Type: sedoc.ArgumentTypeBoolean, Description: ""}
+	//
+	// Deprecated: use Operators(Nullable(Email), sedoc.OperatorEq, sedoc.OperatorNull) and "email[null]" condition
+	EmailNotNull = sedoc.Argument{Name: "email_not_null", Type: sedoc.ArgumentTypeBoolean, Description: "", Deprecated: `use "email[null]" condition`}
 	// CreatedLater argument
-	CreatedLater = sedoc.Argument{Name: "created_later", Type: sedoc.ArgumentTypeTime, Description: "Created later then time"}
+	//
+	// Deprecated: use Created with "[gt]" or "[lt]" condition
+	CreatedLater = sedoc.Argument{Name: "created_later", Type: sedoc.ArgumentTypeTime, Description: "Created later then time", Deprecated: `use "created[gt]" condition`}
 	// CreatedEarlier argument
-	CreatedEarlier = sedoc.Argument{Name: "created_earlier", Type: sedoc.ArgumentTypeTime, Description: "Created earlier then time"}
+	//
+	// Deprecated: use Created with "[gt]" or "[lt]" condition
+	CreatedEarlier = sedoc.Argument{Name: "created_earlier", Type: sedoc.ArgumentTypeTime, Description: "Created earlier then time", Deprecated: `use "created[lt]" condition`}
 	// UpdatedLater argument
-	UpdatedLater = sedoc.Argument{Name: "updated_later", Type: sedoc.ArgumentTypeTime, Description: "Updated later then time"}
+	//
+	// Deprecated: use Updated with "[gt]" or "[lt]" condition
+	UpdatedLater = sedoc.Argument{Name: "updated_later", Type: sedoc.ArgumentTypeTime, Description: "Updated later then time", Deprecated: `use "updated[gt]" condition`}
 	// UpdatedEarlier argument
-	UpdatedEarlier = sedoc.Argument{Name: "updated_earlier", Type: sedoc.ArgumentTypeTime, Description: "Updated earlier then time"}
+	//
+	// Deprecated: use Updated with "[gt]" or "[lt]" condition
+	UpdatedEarlier = sedoc.Argument{Name: "updated_earlier", Type: sedoc.ArgumentTypeTime, Description: "Updated earlier then time", Deprecated: `use "updated[lt]" condition`}
 	// DeletedLater argument
-	DeletedLater = sedoc.Argument{Name: "deleted_later", Type: sedoc.ArgumentTypeTime, Description: "Deleted later then time"}
+	//
+	// Deprecated: use DeletedAt with "[gt]" or "[lt]" condition
+	DeletedLater = sedoc.Argument{Name: "deleted_later", Type: sedoc.ArgumentTypeTime, Description: "Deleted later then time", Deprecated: `use "deleted_at[gt]" condition`}
 	// DeletedEarlier argument
-	DeletedEarlier = sedoc.Argument{Name: "deleted_earlier", Type: sedoc.ArgumentTypeTime, Description: "Deleted earlier then time"}
+	//
+	// Deprecated: use DeletedAt with "[gt]" or "[lt]" condition
+	DeletedEarlier = sedoc.Argument{Name: "deleted_earlier", Type: sedoc.ArgumentTypeTime, Description: "Deleted earlier then time", Deprecated: `use "deleted_at[lt]" condition`}
+	// Created is creation time argument, comparable in Where
+	Created = sedoc.Argument{Name: "created", Type: sedoc.ArgumentTypeTime, Description: "Creation time", Operators: sedoc.ComparisonOperators}
+	// Updated is update time argument, comparable in Where
+	Updated = sedoc.Argument{Name: "updated", Type: sedoc.ArgumentTypeTime, Description: "Update time", Operators: sedoc.ComparisonOperators}
+	// DeletedAt is deletion time argument, comparable in Where
+	DeletedAt = sedoc.Argument{Name: "deleted_at", Type: sedoc.ArgumentTypeTime, Description: "Deletion time", Nullable: true, Operators: append(sedoc.Operators{sedoc.OperatorNull}, sedoc.ComparisonOperators...)}
 	// Count argument
-	Count = sedoc.Argument{Name: "count", Type: sedoc.ArgumentTypeInteger, Description: "Count of items"}
+	Count = sedoc.Argument{Name: sedoc.ArgumentNameCount, Type: sedoc.ArgumentTypeInteger, Description: "Count of items"}
 	// Offset argument
-	Offset = sedoc.Argument{Name: "offset", Type: sedoc.ArgumentTypeInteger, Description: "Items offset"}
+	Offset = sedoc.Argument{Name: sedoc.ArgumentNameOffset, Type: sedoc.ArgumentTypeInteger, Description: "Items offset"}
+	// Cursor argument
+	Cursor = sedoc.Argument{Name: sedoc.ArgumentNameCursor, Type: sedoc.ArgumentTypeString, Description: "Items cursor"}
+	// Sort argument
+	Sort = sedoc.Argument{Name: sedoc.ArgumentNameSort, Type: sedoc.ArgumentTypeString, Multiple: true, Description: "Sort keys (prefixed by `-` for descending order)"}
+	// Fields argument
+	Fields = sedoc.Argument{Name: sedoc.ArgumentNameFields, Type: sedoc.ArgumentTypeString, Multiple: true, Description: "Result fields to return"}
 	// Deleted argument
 	Deleted = sedoc.Argument{Name: "deleted", Type: sedoc.ArgumentTypeBoolean, Description: "Get deleted and undeleted items"}
 	// DeletedOnly argument
@@ -76,6 +123,8 @@ var (
 	RoleID = sedoc.Argument{Name: "role_id", Type: sedoc.ArgumentTypeInteger, Description: "User role identifier", RegExp: RegexpInteger}
 	// RoleUUID is uuid role identifier argument
 	RoleUUID = sedoc.Argument{Name: "role_id", Type: sedoc.ArgumentTypeUUID, Description: "User role identifier", RegExp: RegexpUUID}
+	// IdempotencyKey argument (see sedoc IdempotencyConfig Argument)
+	IdempotencyKey = sedoc.Argument{Name: "idempotency_key", Type: sedoc.ArgumentTypeString, Description: "Idempotency key of retried request"}
 	// FinallyDelete argument
 	FinallyDelete = sedoc.Argument{Name: "finally", Type: sedoc.ArgumentTypeBoolean, Description: "Finally delete"}
 )
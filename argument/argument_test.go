@@ -52,3 +52,13 @@ func TestNullable(t *testing.T) {
 		})
 	}
 }
+
+func TestOperators(t *testing.T) {
+	got := Operators(Created, sedoc.OperatorGt, sedoc.OperatorLt)
+	if !reflect.DeepEqual(got.Operators, sedoc.Operators{sedoc.OperatorGt, sedoc.OperatorLt}) {
+		t.Errorf("Operators() = %v", got.Operators)
+	}
+	if !reflect.DeepEqual(Created.Operators, sedoc.ComparisonOperators) {
+		t.Errorf("Operators() modify source argument: %v", Created.Operators)
+	}
+}
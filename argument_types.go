@@ -0,0 +1,231 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sync"
+)
+
+// ArgumentTypeInfo describes ArgumentType registered in API
+type ArgumentTypeInfo struct {
+	XMLName     xml.Name       `json:"-" xml:"type" yaml:"-"`
+	Type        ArgumentType   `json:"name" xml:"name,attr" yaml:"name"`
+	Description string         `json:"description" xml:"description,attr" yaml:"description"`
+	Example     interface{}    `json:"example,omitempty" xml:"example,omitempty" yaml:"example,omitempty"`
+	Schema      InterfaceMap   `json:"schema,omitempty" xml:"schema,omitempty" yaml:"schema,omitempty"`
+	Encoding    string         `json:"encoding,omitempty" xml:"encoding,attr,omitempty" yaml:"encoding,omitempty"`
+	Parser      ArgumentParser `json:"-" xml:"-" yaml:"-"`
+}
+
+// DefaultArgumentTypes is list of builtin ArgumentTypeInfo
+var DefaultArgumentTypes = []ArgumentTypeInfo{
+	{
+		Type:        ArgumentTypeBoolean,
+		Description: "Boolean value",
+		Example:     true,
+		Schema:      InterfaceMap{"type": "boolean"},
+		Encoding:    "true or false",
+		Parser:      globalParser(ArgumentTypeBoolean),
+	},
+	{
+		Type:        ArgumentTypeInteger,
+		Description: "Integer number",
+		Example:     42,
+		Schema:      InterfaceMap{"type": "integer"},
+		Encoding:    "decimal digits",
+		Parser:      globalParser(ArgumentTypeInteger),
+	},
+	{
+		Type:        ArgumentTypeFloat,
+		Description: "Floating point number",
+		Example:     3.14,
+		Schema:      InterfaceMap{"type": "number"},
+		Encoding:    "decimal number",
+		Parser:      globalParser(ArgumentTypeFloat),
+	},
+	{
+		Type:        ArgumentTypeString,
+		Description: "String value",
+		Example:     "foo",
+		Schema:      InterfaceMap{"type": "string"},
+		Encoding:    "text",
+		Parser:      globalParser(ArgumentTypeString),
+	},
+	{
+		Type:        ArgumentTypeDuration,
+		Description: "Duration, formatted like \"1h15m30.5s\"",
+		Example:     "1h15m30.5s",
+		Schema:      InterfaceMap{"type": "string", "pattern": "^-?([0-9]+(\\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$"},
+		Encoding:    "Go duration string",
+		Parser:      globalParser(ArgumentTypeDuration),
+	},
+	{
+		Type:        ArgumentTypeUUID,
+		Description: "UUID, formatted like \"01234567-89ab-cdef-0123-456789abcdef\"",
+		Example:     "01234567-89ab-cdef-0123-456789abcdef",
+		Schema:      InterfaceMap{"type": "string", "format": "uuid"},
+		Encoding:    "RFC 4122 string",
+		Parser:      globalParser(ArgumentTypeUUID),
+	},
+	{
+		Type:        ArgumentTypeTime,
+		Description: "Datetime string (ISO 8601)",
+		Example:     "2018-10-16T09:58:03Z",
+		Schema:      InterfaceMap{"type": "string", "format": "date-time"},
+		Encoding:    "RFC 3339 string",
+		Parser:      globalParser(ArgumentTypeTime),
+	},
+	{
+		Type:        ArgumentTypeDate,
+		Description: "Calendar date string (ISO 8601), formatted like \"2018-10-16\"",
+		Example:     "2018-10-16",
+		Schema:      InterfaceMap{"type": "string", "format": "date"},
+		Encoding:    "ISO 8601 date string",
+		Parser:      globalParser(ArgumentTypeDate),
+	},
+	{
+		Type:        ArgumentTypeDecimal,
+		Description: "Arbitrary-precision decimal number (money etc), formatted like \"-1234.50\"",
+		Example:     "1234.50",
+		Schema:      InterfaceMap{"type": "string", "pattern": "^[+-]?[0-9]*\\.?[0-9]+([eE][+-]?[0-9]+)?$"},
+		Encoding:    "decimal string (numbers are accepted on input)",
+		Parser:      globalParser(ArgumentTypeDecimal),
+	},
+	{
+		Type:        ArgumentTypeEmail,
+		Description: "Email address without display name",
+		Example:     "user@example.com",
+		Schema:      InterfaceMap{"type": "string", "format": "email"},
+		Encoding:    "RFC 5322 address string",
+		Parser:      globalParser(ArgumentTypeEmail),
+	},
+	{
+		Type:        ArgumentTypeURL,
+		Description: "Absolute URL",
+		Example:     "https://example.com/path?query=1",
+		Schema:      InterfaceMap{"type": "string", "format": "uri"},
+		Encoding:    "RFC 3986 string",
+		Parser:      globalParser(ArgumentTypeURL),
+	},
+	{
+		Type:        ArgumentTypeIP,
+		Description: "IPv4 or IPv6 address",
+		Example:     "192.0.2.1",
+		Schema:      InterfaceMap{"type": "string", "format": "ip"},
+		Encoding:    "dotted decimal (IPv4) or RFC 5952 (IPv6) string",
+		Parser:      globalParser(ArgumentTypeIP),
+	},
+	{
+		Type:        ArgumentTypeCIDR,
+		Description: "IP network in CIDR notation",
+		Example:     "192.0.2.0/24",
+		Schema:      InterfaceMap{"type": "string", "pattern": "^[0-9a-fA-F.:]+/[0-9]{1,3}$"},
+		Encoding:    "CIDR string",
+		Parser:      globalParser(ArgumentTypeCIDR),
+	},
+	{
+		Type:        ArgumentTypeBytes,
+		Description: "Binary data, base64 encoded",
+		Example:     "c2Vkb2M=",
+		Schema:      InterfaceMap{"type": "string", "contentEncoding": "base64"},
+		Encoding:    "RFC 4648 base64 string",
+		Parser:      globalParser(ArgumentTypeBytes),
+	},
+}
+
+// ArgumentTypes is registry of ArgumentTypeInfo. It is safe for concurrent use
+type ArgumentTypes struct {
+	mu    sync.RWMutex
+	types []ArgumentTypeInfo
+}
+
+// NewArgumentTypes is ArgumentTypes constructor
+func NewArgumentTypes(infos ...ArgumentTypeInfo) *ArgumentTypes {
+	r := &ArgumentTypes{}
+	for _, info := range infos {
+		r.Register(info)
+	}
+	return r
+}
+
+// Register add ArgumentTypeInfo to registry. Register will rewrite info with the same Type
+func (r *ArgumentTypes) Register(info ArgumentTypeInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for idx := range r.types {
+		if r.types[idx].Type == info.Type {
+			r.types[idx] = info
+			return
+		}
+	}
+	r.types = append(r.types, info)
+}
+
+// Get return ArgumentTypeInfo by ArgumentType
+func (r *ArgumentTypes) Get(t ArgumentType) (ArgumentTypeInfo, bool) {
+	if r == nil {
+		return ArgumentTypeInfo{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, info := range r.types {
+		if info.Type == t {
+			return info, true
+		}
+	}
+	return ArgumentTypeInfo{}, false
+}
+
+// List return copy of registered ArgumentTypeInfo list
+func (r *ArgumentTypes) List() []ArgumentTypeInfo {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ArgumentTypeInfo{}, r.types...)
+}
+
+// Parse v by parser of registered ArgumentType t.
+// Not registered types are parsed by ArgumentType Parse
+func (r *ArgumentTypes) Parse(t ArgumentType, v interface{}, list bool) (res interface{}, err error) {
+	info, ok := r.Get(t)
+	if !ok {
+		return t.Parse(v, list)
+	}
+	if info.Parser == nil {
+		return nil, fmt.Errorf("nil parser: %s (%v)", t, v)
+	}
+	if res, err = info.Parser(v, list); err != nil {
+		err = fmt.Errorf("api: %v", err)
+	}
+	return
+}
+
+// MarshalJSON for marshal into JSON
+func (r *ArgumentTypes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.List())
+}
+
+// MarshalYAML for marshal into YAML
+func (r *ArgumentTypes) MarshalYAML() (interface{}, error) {
+	return r.List(), nil
+}
+
+// MarshalXML for marshal into XML
+func (r *ArgumentTypes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	a := []interface{}{}
+	for _, item := range r.List() {
+		a = append(a, item)
+	}
+	return MarshallerXML(a)(e, start)
+}
+
+// RegisterArgumentType add ArgumentTypeInfo to API ArgumentTypes
+func (api *API) RegisterArgumentType(info ArgumentTypeInfo) {
+	if api.Types == nil {
+		api.Types = NewArgumentTypes()
+	}
+	api.Types.Register(info)
+}
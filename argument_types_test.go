@@ -0,0 +1,131 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const argumentTypePhone ArgumentType = "phone"
+
+func parsePhone(v interface{}, list bool) (interface{}, error) {
+	s, ok := v.(string)
+	if list || !ok || !strings.HasPrefix(s, "+") {
+		return nil, fmt.Errorf("incompatible phone type: %T (%v)", v, v)
+	}
+	return s, nil
+}
+
+func TestAPI_RegisterArgumentType(t *testing.T) {
+	a, b := New(), New()
+	a.RegisterArgumentType(ArgumentTypeInfo{
+		Type:        argumentTypePhone,
+		Description: "Phone number in E.164",
+		Example:     "+74951234567",
+		Schema:      InterfaceMap{"type": "string", "pattern": "^\\+[1-9]\\d{1,14}$"},
+		Encoding:    "text",
+		Parser:      parsePhone,
+	})
+	cmd := Command{
+		Name:      "call",
+		Arguments: Arguments{{Name: "phone", Type: argumentTypePhone}},
+		Handler:   func(c Context) error { return nil },
+	}
+	a.AddCommand(cmd)
+	b.AddCommand(cmd)
+	tests := []struct {
+		name     string
+		api      *API
+		phone    string
+		wantCode int
+	}{
+		{"registered", a, "+74951234567", 0},
+		{"registered invalid", a, "84951234567", ErrInvalidArgumentValue},
+		{"other api", b, "+74951234567", ErrInvalidArgumentValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.api.Execute(&Request{Command: "call", Arguments: InterfaceMap{"phone": tt.phone}})
+			code := 0
+			if resp.Error != nil {
+				code = resp.Error.Code
+			}
+			if code != tt.wantCode {
+				t.Errorf("Execute() error = %v, want code %d", resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestArgumentTypes_help(t *testing.T) {
+	a := New()
+	a.RegisterArgumentType(ArgumentTypeInfo{Type: argumentTypePhone, Description: "Phone number", Parser: parsePhone})
+	resp := a.Execute(&Request{Command: "help"})
+	if resp.Error != nil {
+		t.Fatalf("Execute(help) error = %v", resp.Error)
+	}
+	marshalers := map[string]func(interface{}) ([]byte, error){
+		"json": json.Marshal,
+		"xml":  xml.Marshal,
+		"yaml": yaml.Marshal,
+	}
+	for name, marshal := range marshalers {
+		b, err := marshal(resp)
+		if err != nil {
+			t.Fatalf("%s: Marshal() error = %v", name, err)
+		}
+		for _, info := range a.Types.List() {
+			if !strings.Contains(string(b), string(info.Type)) {
+				t.Errorf("%s: help does not contain type %s", name, info.Type)
+			}
+		}
+		if !strings.Contains(string(b), "Phone number") {
+			t.Errorf("%s: help does not contain type description", name)
+		}
+	}
+}
+
+func TestArgumentTypes_concurrent(t *testing.T) {
+	r := NewArgumentTypes(DefaultArgumentTypes...)
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < 8; idx++ {
+		wg.Add(2)
+		go func(idx int) {
+			defer wg.Done()
+			r.Register(ArgumentTypeInfo{Type: ArgumentType(fmt.Sprint("t", idx)), Parser: parseString})
+		}(idx)
+		go func() {
+			defer wg.Done()
+			_, _ = r.Parse(ArgumentTypeInteger, "1", false)
+		}()
+	}
+	wg.Wait()
+	if got := len(r.List()); got != len(DefaultArgumentTypes)+8 {
+		t.Errorf("ArgumentTypes.List() len = %d, want %d", got, len(DefaultArgumentTypes)+8)
+	}
+}
+
+func TestSetArgumentParser_builtin(t *testing.T) {
+	SetArgumentParser(ArgumentTypeString, func(v interface{}, list bool) (interface{}, error) {
+		return strings.ToUpper(fmt.Sprint(v)), nil
+	})
+	defer SetArgumentParser(ArgumentTypeString, parseString)
+	a := New()
+	a.AddCommand(Command{
+		Name:      "echo",
+		Arguments: Arguments{{Name: "text", Type: ArgumentTypeString}},
+		Handler: func(c Context) error {
+			c.Response().Result = c.Request().Arguments["text"]
+			return nil
+		},
+	})
+	resp := a.Execute(&Request{Command: "echo", Arguments: InterfaceMap{"text": "foo"}})
+	if resp.Error != nil || resp.Result != "FOO" {
+		t.Errorf("Execute() = %v, %v, want global parser result FOO", resp.Result, resp.Error)
+	}
+}
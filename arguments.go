@@ -16,7 +16,12 @@ type Argument struct {
 	Multiple    bool         `json:"multiple,omitempty" xml:"multiple,attr,omitempty" yaml:"multiple,omitempty"`
 	Required    bool         `json:"required,omitempty" xml:"required,attr,omitempty" yaml:"required,omitempty"`
 	Disabled    bool         `json:"-" xml:"-" yaml:"-"`
+	Column      string       `json:"-" xml:"-" yaml:"-"`
 	RegExp      string       `json:"regexp,omitempty" xml:"regexp,attr,omitempty" yaml:"regexp,omitempty"`
+	Operators   Operators    `json:"operators,omitempty" xml:"operators,attr,omitempty" yaml:"operators,omitempty"`
+	Fields      Arguments    `json:"fields,omitempty" xml:"fields,omitempty" yaml:"fields,omitempty"`
+	Deprecated  string       `json:"deprecated,omitempty" xml:"deprecated,attr,omitempty" yaml:"deprecated,omitempty"`
+	Sensitive   bool         `json:"sensitive,omitempty" xml:"sensitive,attr,omitempty" yaml:"sensitive,omitempty"`
 }
 
 // Arguments is array of Argument
@@ -101,9 +106,25 @@ const (
 	ArgumentTypeUUID ArgumentType = "uuid"
 	// ArgumentTypeTime is datetime Argument type
 	ArgumentTypeTime ArgumentType = "datetime"
+	// ArgumentTypeDate is calendar date Argument type
+	ArgumentTypeDate ArgumentType = "date"
+	// ArgumentTypeDecimal is arbitrary-precision decimal Argument type
+	ArgumentTypeDecimal ArgumentType = "decimal"
+	// ArgumentTypeEmail is email address Argument type
+	ArgumentTypeEmail ArgumentType = "email"
+	// ArgumentTypeURL is absolute URL Argument type
+	ArgumentTypeURL ArgumentType = "url"
+	// ArgumentTypeIP is IP address Argument type
+	ArgumentTypeIP ArgumentType = "ip"
+	// ArgumentTypeCIDR is IP network (CIDR notation) Argument type
+	ArgumentTypeCIDR ArgumentType = "cidr"
+	// ArgumentTypeBytes is base64 encoded binary Argument type
+	ArgumentTypeBytes ArgumentType = "base64"
 
-	argumentTypeObject ArgumentType = "object"
-	argumentTypeArray  ArgumentType = "array"
+	// ArgumentTypeObject is object Argument type, it's fields may be described by Argument Fields
+	ArgumentTypeObject ArgumentType = "object"
+	// ArgumentTypeArray is array Argument type
+	ArgumentTypeArray ArgumentType = "array"
 
 	// ArgumentTypeList is duration Argument type
 	ArgumentTypeList ArgumentType = "list"
@@ -1,6 +1,9 @@
 package sedoc
 
 import (
+	"net"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -51,6 +54,8 @@ func TestArgumentType_Parse(t *testing.T) {
 		{`integer(-1)`, ArgumentTypeInteger, args{-1, false}, -1, false},
 		{`integer(15.2)`, ArgumentTypeInteger, args{15.2, false}, 15, false},
 		{`integer(-8)`, ArgumentTypeInteger, args{-8, false}, -8, false},
+		{`integer("200")`, ArgumentTypeInteger, args{"200", false}, 200, false},
+		{`integer("-70000")`, ArgumentTypeInteger, args{"-70000", false}, -70000, false},
 		{`integer("-5.8")`, ArgumentTypeInteger, args{"-5.8", false}, 0, true},
 		{`integer("false")`, ArgumentTypeInteger, args{"false", false}, 0, true},
 		{`integer("true")`, ArgumentTypeInteger, args{"true", false}, 0, true},
@@ -109,6 +114,32 @@ func TestArgumentType_Parse(t *testing.T) {
 		{`string("1.5h25s")`, ArgumentTypeString, args{"1.5h25s", false}, "1.5h25s", false},
 		{`string("-15m8s")`, ArgumentTypeString, args{"-15m8s", false}, "-15m8s", false},
 
+		{`date("2018-10-16")`, ArgumentTypeDate, args{"2018-10-16", false}, types.Date{Year: 2018, Month: time.October, Day: 16}, false},
+		{`date(time.Time)`, ArgumentTypeDate, args{time.Date(2018, 10, 16, 9, 58, 3, 0, time.UTC), false}, types.Date{Year: 2018, Month: time.October, Day: 16}, false},
+		{`date("2018-10-16T09:58:03Z")`, ArgumentTypeDate, args{"2018-10-16T09:58:03Z", false}, types.Date{}, true},
+		{`multi_date`, ArgumentTypeDate, args{[]interface{}{"2018-10-16", "2019-01-02"}, true}, []types.Date{{Year: 2018, Month: time.October, Day: 16}, {Year: 2019, Month: time.January, Day: 2}}, false},
+		{`decimal("12.50")`, ArgumentTypeDecimal, args{"12.50", false}, types.NewDecimal(1250, 2), false},
+		{`decimal(12)`, ArgumentTypeDecimal, args{12, false}, types.NewDecimal(12, 0), false},
+		{`decimal(0.25)`, ArgumentTypeDecimal, args{0.25, false}, types.NewDecimal(25, 2), false},
+		{`decimal("foo")`, ArgumentTypeDecimal, args{"foo", false}, types.Decimal{}, true},
+		{`email("user@example.com")`, ArgumentTypeEmail, args{"user@example.com", false}, types.Email("user@example.com"), false},
+		{`email("User <user@example.com>")`, ArgumentTypeEmail, args{"User <user@example.com>", false}, types.Email(""), true},
+		{`email(1)`, ArgumentTypeEmail, args{1, false}, types.Email(""), true},
+		{`multi_email([]types.Email)`, ArgumentTypeEmail, args{[]types.Email{"user@example.com"}, true}, []types.Email{"user@example.com"}, false},
+		{`multi_email([]types.Email{"foo"})`, ArgumentTypeEmail, args{[]types.Email{"user@example.com", "foo"}, true}, []types.Email(nil), true},
+		{`url("https://example.com/a")`, ArgumentTypeURL, args{"https://example.com/a", false}, types.URL{URL: url.URL{Scheme: "https", Host: "example.com", Path: "/a"}}, false},
+		{`url("/a")`, ArgumentTypeURL, args{"/a", false}, types.URL{}, true},
+		{`ip("192.0.2.1")`, ArgumentTypeIP, args{"192.0.2.1", false}, types.IP{Addr: netip.MustParseAddr("192.0.2.1")}, false},
+		{`ip(net.IP)`, ArgumentTypeIP, args{net.ParseIP("192.0.2.1"), false}, types.IP{Addr: netip.MustParseAddr("192.0.2.1")}, false},
+		{`ip("192.0.2")`, ArgumentTypeIP, args{"192.0.2", false}, types.IP{}, true},
+		{`multi_ip`, ArgumentTypeIP, args{[]string{"::1"}, true}, []types.IP{{Addr: netip.MustParseAddr("::1")}}, false},
+		{`cidr("192.0.2.0/24")`, ArgumentTypeCIDR, args{"192.0.2.0/24", false}, types.CIDR{Prefix: netip.MustParsePrefix("192.0.2.0/24")}, false},
+		{`cidr("192.0.2.0")`, ArgumentTypeCIDR, args{"192.0.2.0", false}, types.CIDR{}, true},
+		{`base64("c2Vkb2M=")`, ArgumentTypeBytes, args{"c2Vkb2M=", false}, types.Bytes("sedoc"), false},
+		{`base64("c2Vkb2M")`, ArgumentTypeBytes, args{"c2Vkb2M", false}, types.Bytes("sedoc"), false},
+		{`base64([]byte)`, ArgumentTypeBytes, args{[]byte("sedoc"), false}, types.Bytes("sedoc"), false},
+		{`base64("!")`, ArgumentTypeBytes, args{"!", false}, types.Bytes(nil), true},
+
 		{`incompatible_milti_boolean_type`, ArgumentTypeBoolean, args{nil, true}, nil, true},
 		{`milti_boolean([]bool{false,true})`, ArgumentTypeBoolean, args{[]bool{false, true}, true}, []bool{false, true}, false},
 	}
@@ -0,0 +1,325 @@
+package sedoc
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+// Struct tags used by Bind and ArgumentsOf:
+//
+//	ID    int64     `sedoc:"id,where,required" desc:"Identifier"`
+//	Login string    `sedoc:"login,set" regexp:"^\\w+$"`
+//	Count *int      `sedoc:"count"`
+//	IDs   []string  `sedoc:"ids"`
+//
+// First item of sedoc tag is Argument name ("-" skip field), other items are
+// options: args (default), where, set, required, nullable. Optional desc,
+// regexp and column tags fill Argument Description, RegExp and Column.
+// Pointer fields are nullable, slice fields are multiple. Fields without
+// sedoc tag are skipped, anonymous struct fields are walked recursively.
+const (
+	tagName        = "sedoc"
+	tagDescription = "desc"
+	tagRegExp      = "regexp"
+	tagColumn      = "column"
+)
+
+const (
+	sectionArguments = "args"
+	sectionWhere     = "where"
+	sectionSet       = "set"
+)
+
+var goArgumentTypes = map[reflect.Type]ArgumentType{
+	reflect.TypeOf(time.Duration(0)):  ArgumentTypeDuration,
+	reflect.TypeOf(types.Duration(0)): ArgumentTypeDuration,
+	reflect.TypeOf(uuid.UUID{}):       ArgumentTypeUUID,
+	reflect.TypeOf(types.UUID{}):      ArgumentTypeUUID,
+	reflect.TypeOf(time.Time{}):       ArgumentTypeTime,
+	reflect.TypeOf(types.Date{}):      ArgumentTypeDate,
+	reflect.TypeOf(types.Decimal{}):   ArgumentTypeDecimal,
+	reflect.TypeOf(types.Email("")):   ArgumentTypeEmail,
+	reflect.TypeOf(types.URL{}):       ArgumentTypeURL,
+	reflect.TypeOf(url.URL{}):         ArgumentTypeURL,
+	reflect.TypeOf(types.IP{}):        ArgumentTypeIP,
+	reflect.TypeOf(netip.Addr{}):      ArgumentTypeIP,
+	reflect.TypeOf(types.CIDR{}):      ArgumentTypeCIDR,
+	reflect.TypeOf(netip.Prefix{}):    ArgumentTypeCIDR,
+	reflect.TypeOf(types.Bytes{}):     ArgumentTypeBytes,
+}
+
+// typesPkgPath is import path of types package, its single field wrappers
+// (types.UUID, types.IP etc) are unwrapped on assign
+var typesPkgPath = reflect.TypeOf(types.Duration(0)).PkgPath()
+
+type boundField struct {
+	index    []int
+	section  string
+	argument Argument
+}
+
+// ArgumentsOf derive Arguments, Where and Set from tagged struct (or pointer to struct)
+func ArgumentsOf(v interface{}) (args, where, set Arguments, err error) {
+	var fields []boundField
+	if fields, err = structFields(reflect.TypeOf(v)); err != nil {
+		return
+	}
+	for _, f := range fields {
+		switch f.section {
+		case sectionWhere:
+			where = append(where, f.argument)
+		case sectionSet:
+			set = append(set, f.argument)
+		default:
+			args = append(args, f.argument)
+		}
+	}
+	return
+}
+
+// ArgumentsOf replace Arguments, Where and Set of Command by derived from tagged struct
+func (cmd *Command) ArgumentsOf(v interface{}) (err error) {
+	var args, where, set Arguments
+	if args, where, set, err = ArgumentsOf(v); err == nil {
+		cmd.Arguments, cmd.Where, cmd.Set = args, where, set
+	}
+	return
+}
+
+// Bind fill tagged struct dst from Arguments, Set and first item of Where.
+// Where fields are bound from equality conditions, operator conditions of
+// bound fields fail with ErrInvalidArgumentValue (use Filter for them)
+func (c *context) Bind(dst interface{}) error {
+	var types *ArgumentTypes
+	if c.api != nil {
+		types = c.api.Types
+	}
+	return bind(c.Request(), dst, types)
+}
+
+func bind(request *Request, dst interface{}, types *ArgumentTypes) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("sedoc: bind destination must be non-nil pointer to struct, got %T", dst)
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	where := InterfaceMap{}
+	if len(request.Where) > 0 {
+		if where, err = normalizeWhere(request.Where[0]); err != nil {
+			return bindError("where[0]: ", "", err)
+		}
+	}
+	// operator conditions (e.g. "created[gt]") can not be bound to field
+	conditions := map[string]string{}
+	for key := range where {
+		if field, op, _ := ParseWhereKey(key); op != OperatorEq {
+			conditions[field] = key
+		}
+	}
+	for _, f := range fields {
+		params, prefix := request.Arguments, "args: "
+		switch f.section {
+		case sectionWhere:
+			params, prefix = where, "where[0]: "
+			if key, ok := conditions[f.argument.Name]; ok {
+				return bindError(prefix, f.argument.Name, fmt.Errorf("operator condition %s can not be bound", key))
+			}
+		case sectionSet:
+			params, prefix = request.Set, "set: "
+		}
+		val, ok := params[f.argument.Name]
+		if !ok {
+			continue
+		}
+		field := rv.Elem().FieldByIndex(f.index)
+		if val != nil {
+			if val, err = types.Parse(f.argument.Type, val, f.argument.Multiple); err != nil {
+				return bindError(prefix, f.argument.Name, err)
+			}
+		}
+		if err = assign(field, val); err != nil {
+			return bindError(prefix, f.argument.Name, err)
+		}
+	}
+	return nil
+}
+
+func bindError(prefix, name string, internal error) *Error {
+	err := DefaultErrors.Get(ErrInvalidArgumentValue)
+	err.Description = fmt.Sprintf("%s%s (%s)", prefix, err.Description, name)
+	err.Details = []ErrorDetail{fieldDetail(prefix, name, internal.Error())}
+	err.Internal = internal
+	return &err
+}
+
+func structFields(t reflect.Type) (fields []boundField, err error) {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sedoc: struct expected, got %v", t)
+	}
+	for idx := 0; idx < t.NumField(); idx++ {
+		sf := t.Field(idx)
+		tag, tagged := sf.Tag.Lookup(tagName)
+		if !tagged {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				var embedded []boundField
+				if embedded, err = structFields(sf.Type); err != nil {
+					return
+				}
+				for _, f := range embedded {
+					f.index = append([]int{idx}, f.index...)
+					fields = append(fields, f)
+				}
+			}
+			continue
+		}
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		f := boundField{index: []int{idx}, section: sectionArguments}
+		opts := strings.Split(tag, ",")
+		f.argument.Name = opts[0]
+		if len(f.argument.Name) == 0 {
+			f.argument.Name = strings.ToLower(sf.Name)
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case sectionArguments, sectionWhere, sectionSet:
+				f.section = opt
+			case "required":
+				f.argument.Required = true
+			case "nullable":
+				f.argument.Nullable = true
+			case "":
+			default:
+				return nil, fmt.Errorf("sedoc: unknown option %q of field %s", opt, sf.Name)
+			}
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			f.argument.Nullable = true
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Slice && goArgumentTypes[ft] == "" {
+			f.argument.Multiple = true
+			ft = ft.Elem()
+		}
+		if f.argument.Type, err = argumentTypeOf(ft); err != nil {
+			return nil, fmt.Errorf("sedoc: field %s: %v", sf.Name, err)
+		}
+		f.argument.Description = sf.Tag.Get(tagDescription)
+		f.argument.RegExp = sf.Tag.Get(tagRegExp)
+		f.argument.Column = sf.Tag.Get(tagColumn)
+		fields = append(fields, f)
+	}
+	return
+}
+
+func argumentTypeOf(t reflect.Type) (ArgumentType, error) {
+	if at, ok := goArgumentTypes[t]; ok {
+		return at, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return ArgumentTypeBoolean, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ArgumentTypeInteger, nil
+	case reflect.Float32, reflect.Float64:
+		return ArgumentTypeFloat, nil
+	case reflect.String:
+		return ArgumentTypeString, nil
+	}
+	return "", fmt.Errorf("unsupported type %v", t)
+}
+
+func assign(dst reflect.Value, v interface{}) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		ptr := reflect.New(dst.Type().Elem())
+		if err := assign(ptr.Elem(), v); err != nil {
+			return err
+		}
+		dst.Set(ptr)
+		return nil
+	}
+	src := reflect.ValueOf(v)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if src.Kind() == reflect.Struct && src.Type().PkgPath() == typesPkgPath && src.NumField() == 1 && src.Type().Field(0).Anonymous {
+		// unwrap types.UUID, types.IP etc into wrapped type
+		return assign(dst, src.Field(0).Interface())
+	}
+	if dst.Kind() == reflect.Slice && src.Kind() == reflect.Slice {
+		arr := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for idx := 0; idx < src.Len(); idx++ {
+			if err := assign(arr.Index(idx), src.Index(idx).Interface()); err != nil {
+				return err
+			}
+		}
+		dst.Set(arr)
+		return nil
+	}
+	if dst.Kind() != reflect.String && src.Type().ConvertibleTo(dst.Type()) {
+		if err := checkOverflow(dst, src); err != nil {
+			return err
+		}
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("incompatible value type: %T into %v", v, dst.Type())
+}
+
+// checkOverflow return error if numeric src does not fit into dst type
+func checkOverflow(dst, src reflect.Value) error {
+	var overflow bool
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			overflow = dst.OverflowInt(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			overflow = src.Uint() > math.MaxInt64 || dst.OverflowInt(int64(src.Uint()))
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			overflow = math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			overflow = src.Int() < 0 || dst.OverflowUint(uint64(src.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			overflow = dst.OverflowUint(src.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			overflow = math.IsNaN(f) || f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f))
+		}
+	case reflect.Float32, reflect.Float64:
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			overflow = dst.OverflowFloat(src.Float())
+		}
+	}
+	if overflow {
+		return fmt.Errorf("value %v overflows %v", src.Interface(), dst.Type())
+	}
+	return nil
+}
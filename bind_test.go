@@ -0,0 +1,185 @@
+package sedoc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+type bindPaging struct {
+	Count  int `sedoc:"count" desc:"Count of items"`
+	Offset int `sedoc:"offset"`
+}
+
+type bindUser struct {
+	bindPaging
+	ID       int64         `sedoc:"id,where,required" regexp:"^\\d+$"`
+	Session  uuid.UUID     `sedoc:"session"`
+	Login    *string       `sedoc:"login,set"`
+	Tags     []string      `sedoc:"tags,set"`
+	Timeout  time.Duration `sedoc:"timeout"`
+	Created  time.Time     `sedoc:"created,where"`
+	Internal string
+	Skipped  string `sedoc:"-"`
+}
+
+func TestArgumentsOf(t *testing.T) {
+	args, where, set, err := ArgumentsOf(bindUser{})
+	if err != nil {
+		t.Fatalf("ArgumentsOf() error = %v", err)
+	}
+	wantArgs := Arguments{
+		{Name: "count", Type: ArgumentTypeInteger, Description: "Count of items"},
+		{Name: "offset", Type: ArgumentTypeInteger},
+		{Name: "session", Type: ArgumentTypeUUID},
+		{Name: "timeout", Type: ArgumentTypeDuration},
+	}
+	wantWhere := Arguments{
+		{Name: "id", Type: ArgumentTypeInteger, Required: true, RegExp: "^\\d+$"},
+		{Name: "created", Type: ArgumentTypeTime},
+	}
+	wantSet := Arguments{
+		{Name: "login", Type: ArgumentTypeString, Nullable: true},
+		{Name: "tags", Type: ArgumentTypeString, Multiple: true},
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("ArgumentsOf() args = %v, want %v", args, wantArgs)
+	}
+	if !reflect.DeepEqual(where, wantWhere) {
+		t.Errorf("ArgumentsOf() where = %v, want %v", where, wantWhere)
+	}
+	if !reflect.DeepEqual(set, wantSet) {
+		t.Errorf("ArgumentsOf() set = %v, want %v", set, wantSet)
+	}
+	if _, _, _, err = ArgumentsOf(0); err == nil {
+		t.Errorf("ArgumentsOf(0) want error, but not")
+	}
+	if _, _, _, err = ArgumentsOf(struct {
+		C chan int `sedoc:"c"`
+	}{}); err == nil {
+		t.Errorf("ArgumentsOf(chan) want error, but not")
+	}
+}
+
+func Test_context_Bind(t *testing.T) {
+	id := uuid.New()
+	created := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	login := "user"
+	tests := []struct {
+		name    string
+		req     *Request
+		want    bindUser
+		wantErr bool
+	}{
+		{"empty", NewRequest(), bindUser{}, false},
+		{"parsed", &Request{
+			Arguments: InterfaceMap{"count": 10, "session": types.UUID{UUID: id}, "timeout": types.Duration(time.Second)},
+			Where:     []InterfaceMap{{"id": 5, "created": created}},
+			Set:       InterfaceMap{"login": "user", "tags": []string{"a", "b"}},
+		}, bindUser{
+			bindPaging: bindPaging{Count: 10},
+			ID:         5,
+			Session:    id,
+			Login:      &login,
+			Tags:       []string{"a", "b"},
+			Timeout:    time.Second,
+			Created:    created,
+		}, false},
+		{"raw", &Request{
+			Arguments: InterfaceMap{"offset": "7", "session": id.String(), "timeout": "1m"},
+			Where:     []InterfaceMap{{"id": float64(3), "created": "2019-01-02T03:04:05Z"}},
+			Set:       InterfaceMap{"login": nil, "tags": []interface{}{"c"}},
+		}, bindUser{
+			bindPaging: bindPaging{Offset: 7},
+			ID:         3,
+			Session:    id,
+			Tags:       []string{"c"},
+			Timeout:    time.Minute,
+			Created:    created,
+		}, false},
+		{"invalid", &Request{
+			Arguments: InterfaceMap{"timeout": "forever"},
+		}, bindUser{}, true},
+		{"nested eq", &Request{
+			Where: []InterfaceMap{{"id": map[string]interface{}{"eq": 4}}},
+		}, bindUser{ID: 4}, false},
+		{"operator", &Request{
+			Where: []InterfaceMap{{"id": 5, "created[gt]": created}},
+		}, bindUser{}, true},
+		{"nested operator", &Request{
+			Where: []InterfaceMap{{"created": map[string]interface{}{"lt": created}}},
+		}, bindUser{}, true},
+		{"unbound operator", &Request{
+			Where: []InterfaceMap{{"id": 5, "name[like]": "fo%"}},
+		}, bindUser{ID: 5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &context{api: New(), req: tt.req}
+			got := bindUser{}
+			err := c.Bind(&got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("context.Bind() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("context.Bind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	c := &context{api: New(), req: NewRequest()}
+	if err := c.Bind(bindUser{}); err == nil {
+		t.Errorf("context.Bind(struct) want error, but not")
+	}
+}
+
+func Test_assign_wrapper(t *testing.T) {
+	type userDuration struct{ time.Duration }
+	id := uuid.New()
+	var gotID uuid.UUID
+	if err := assign(reflect.ValueOf(&gotID).Elem(), types.UUID{UUID: id}); err != nil || gotID != id {
+		t.Errorf("assign(types.UUID) = %v, %v", gotID, err)
+	}
+	var gotDuration time.Duration
+	if err := assign(reflect.ValueOf(&gotDuration).Elem(), userDuration{time.Second}); err == nil {
+		t.Errorf("assign(userDuration) = %v, want error", gotDuration)
+	}
+}
+
+func Test_context_Bind_overflow(t *testing.T) {
+	type bindNumbers struct {
+		Small int8    `sedoc:"small"`
+		Count uint    `sedoc:"count"`
+		Ratio float32 `sedoc:"ratio"`
+	}
+	tests := []struct {
+		name    string
+		args    InterfaceMap
+		want    bindNumbers
+		wantErr bool
+	}{
+		{"in range", InterfaceMap{"small": -128, "count": 300, "ratio": 0.5}, bindNumbers{Small: -128, Count: 300, Ratio: 0.5}, false},
+		{"int8 overflow", InterfaceMap{"small": 300}, bindNumbers{}, true},
+		{"negative uint", InterfaceMap{"count": -1}, bindNumbers{}, true},
+		{"float32 overflow", InterfaceMap{"ratio": 1e300}, bindNumbers{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &context{api: New(), req: &Request{Arguments: tt.args}}
+			got := bindNumbers{}
+			err := c.Bind(&got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("context.Bind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if serr, ok := err.(*Error); tt.wantErr && (!ok || serr.Code != ErrInvalidArgumentValue) {
+				t.Errorf("context.Bind() error = %v, want code %d", err, ErrInvalidArgumentValue)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("context.Bind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+/*
+Package cbor is CBOR (RFC 8949) encoding of Go values. Values are mapped
+like encoding/json does: by json struct tags, with json.Marshaler and
+encoding.TextMarshaler support. time.Time is encoded as standard date/time
+string (tag 0), []byte as byte string.
+
+Decoded into interface{} values are nil, bool, int64 (uint64 if it overflows
+int64), float64, string, []byte, time.Time, []interface{} and
+map[string]interface{}. Indefinite length items are accepted by Unmarshal.
+*/
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nsemikov/go-sedoc/internal/codec"
+)
+
+// major types
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// tags of date/time
+const (
+	tagDateTime = 0
+	tagEpoch    = 1
+)
+
+const (
+	simpleFalse     = 20
+	simpleTrue      = 21
+	simpleNull      = 22
+	simpleUndefined = 23
+	infoIndefinite  = 31
+	codeBreak       = 0xff
+)
+
+// Marshal return CBOR encoding of v
+func Marshal(v interface{}) ([]byte, error) {
+	g, err := codec.ToValue(v)
+	if err != nil {
+		return nil, err
+	}
+	e := &encoder{}
+	if err = e.encode(g); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal parse CBOR data into value pointed by v
+func Unmarshal(data []byte, v interface{}) error {
+	d := &decoder{data: data}
+	g, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if g == errBreak {
+		return fmt.Errorf("cbor: unexpected break")
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("cbor: %d bytes of trailing data", len(d.data)-d.pos)
+	}
+	return codec.FromValue(g, v)
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) head(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, major|25)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, major|26)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, major|27)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+func (e *encoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, majorSimple<<5|simpleNull)
+	case bool:
+		if v {
+			e.buf = append(e.buf, majorSimple<<5|simpleTrue)
+		} else {
+			e.buf = append(e.buf, majorSimple<<5|simpleFalse)
+		}
+	case int64:
+		if v >= 0 {
+			e.head(majorUint, uint64(v))
+		} else {
+			e.head(majorNegInt, uint64(-1-v))
+		}
+	case uint64:
+		e.head(majorUint, v)
+	case float32:
+		e.buf = append(e.buf, majorSimple<<5|26)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(v))
+	case float64:
+		e.buf = append(e.buf, majorSimple<<5|27)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v))
+	case string:
+		e.head(majorText, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	case []byte:
+		e.head(majorBytes, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	case time.Time:
+		s := v.Format(time.RFC3339Nano)
+		e.head(majorTag, tagDateTime)
+		e.head(majorText, uint64(len(s)))
+		e.buf = append(e.buf, s...)
+	case []interface{}:
+		e.head(majorArray, uint64(len(v)))
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case *codec.Map:
+		e.head(majorMap, uint64(len(v.Keys)))
+		for idx, key := range v.Keys {
+			e.head(majorText, uint64(len(key)))
+			e.buf = append(e.buf, key...)
+			if err := e.encode(v.Values[idx]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cbor: unsupported value: %T", v)
+	}
+	return nil
+}
+
+var (
+	errShort = errors.New("cbor: unexpected end of data")
+	// errBreak is returned as value of break stop code of indefinite length item
+	errBreak = errors.New("cbor: break")
+)
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errShort
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// argument read head argument of additional info; indefinite is reported by ok false
+func (d *decoder) argument(info byte) (n uint64, ok bool, err error) {
+	switch {
+	case info < 24:
+		return uint64(info), true, nil
+	case info == infoIndefinite:
+		return 0, false, nil
+	case info > 27:
+		return 0, false, fmt.Errorf("cbor: invalid additional info %d", info)
+	}
+	b, err := d.next(1 << (info - 24))
+	if err != nil {
+		return 0, false, err
+	}
+	switch len(b) {
+	case 1:
+		return uint64(b[0]), true, nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), true, nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), true, nil
+	}
+	return binary.BigEndian.Uint64(b), true, nil
+}
+
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > codec.MaxDepth {
+		return nil, codec.ErrMaxDepth
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] == codeBreak {
+		return errBreak, nil
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+	if major == majorSimple {
+		return d.simple(info)
+	}
+	n, definite, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+	if !definite && (major == majorUint || major == majorNegInt || major == majorTag) {
+		return nil, fmt.Errorf("cbor: invalid indefinite length of major type %d", major)
+	}
+	switch major {
+	case majorUint:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(n), nil
+	case majorBytes, majorText:
+		s, err := d.str(major, n, definite, depth)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(s), nil
+		}
+		return s, nil
+	case majorArray:
+		var res []interface{}
+		for idx := uint64(0); !definite || idx < n; idx++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if item == errBreak {
+				if definite {
+					return nil, errBreak
+				}
+				break
+			}
+			res = append(res, item)
+		}
+		if res == nil {
+			res = []interface{}{}
+		}
+		return res, nil
+	case majorMap:
+		res := map[string]interface{}{}
+		for idx := uint64(0); !definite || idx < n; idx++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if key == errBreak {
+				if definite {
+					return nil, errBreak
+				}
+				break
+			}
+			val, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if val == errBreak {
+				return nil, errBreak
+			}
+			res[codec.Key(key)] = val
+		}
+		return res, nil
+	}
+	// majorTag
+	content, err := d.decode(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if content == errBreak {
+		return nil, errBreak
+	}
+	switch n {
+	case tagDateTime:
+		s, ok := content.(string)
+		if !ok {
+			return nil, fmt.Errorf("cbor: invalid date/time string: %T", content)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case tagEpoch:
+		switch v := content.(type) {
+		case int64:
+			return time.Unix(v, 0).UTC(), nil
+		case uint64:
+			return time.Unix(int64(v), 0).UTC(), nil
+		case float64:
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+		}
+		return nil, fmt.Errorf("cbor: invalid epoch date/time: %T", content)
+	}
+	return content, nil
+}
+
+// str read byte or text string, indefinite one is concatenation of definite chunks
+func (d *decoder) str(major byte, n uint64, definite bool, depth int) ([]byte, error) {
+	if definite {
+		b, err := d.next(n)
+		return append([]byte{}, b...), err
+	}
+	res := []byte{}
+	for {
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == codeBreak {
+			return res, nil
+		}
+		if b[0]>>5 != major {
+			return nil, fmt.Errorf("cbor: invalid chunk of indefinite length string")
+		}
+		size, ok, err := d.argument(b[0] & 0x1f)
+		if err != nil || !ok {
+			return nil, fmt.Errorf("cbor: invalid chunk of indefinite length string")
+		}
+		chunk, err := d.next(size)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chunk...)
+	}
+}
+
+func (d *decoder) simple(info byte) (interface{}, error) {
+	switch info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull, simpleUndefined:
+		return nil, nil
+	case 25:
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+		return halfToFloat64(binary.BigEndian.Uint16(b)), nil
+	case 26:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 27:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
+
+// halfToFloat64 convert IEEE 754 half precision float
+func halfToFloat64(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
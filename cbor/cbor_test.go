@@ -0,0 +1,134 @@
+package cbor_test
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+	"github.com/nsemikov/go-sedoc/cbor"
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+// vectors are from RFC 8949 Appendix A
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"0", 0, "00"},
+		{"23", 23, "17"},
+		{"24", 24, "1818"},
+		{"1000", 1000, "1903e8"},
+		{"max uint64", uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{"-1", -1, "20"},
+		{"-1000", -1000, "3903e7"},
+		{"float64", 1.1, "fb3ff199999999999a"},
+		{"float32", float32(100000), "fa47c35000"},
+		{"false", false, "f4"},
+		{"null", nil, "f6"},
+		{"text", "IETF", "6449455446"},
+		{"bytes", []byte{1, 2, 3, 4}, "4401020304"},
+		{"array", []int{1, 2, 3}, "83010203"},
+		{"map", map[string]interface{}{"a": 1, "b": []int{2, 3}}, "a26161016162820203"},
+		{"time", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c074323031332d30332d32315432303a30343a30305a"},
+		{"json marshaler", types.Duration(time.Second), "623173"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := cbor.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(b); got != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		{"uint64", "1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"negative", "3903e7", int64(-1000)},
+		{"float16", "f93e00", 1.5},
+		{"float16 subnormal", "f90001", 5.960464477539063e-8},
+		{"float16 inf", "f9fc00", math.Inf(-1)},
+		{"undefined", "f7", nil},
+		{"epoch", "c11a514b67b0", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"epoch float", "c1fb41d452d9ec200000", time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC)},
+		{"unknown tag", "d74401020304", []byte{1, 2, 3, 4}},
+		{"indefinite bytes", "5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"indefinite text", "7f657374726561646d696e67ff", "streaming"},
+		{"indefinite array", "9f018202039f0405ffff", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"indefinite map", "bf61610161629f0203ffff", map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{"int key", "a10102", map[string]interface{}{"1": int64(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := hex.DecodeString(tt.data)
+			var got interface{}
+			if err := cbor.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_invalid(t *testing.T) {
+	for _, data := range []string{"", "ff", "1c", "62", "9bffffffffffffffff", "3bffffffffffffffff", "0101", "82ff", "5f01ff", "c001", "f8"} {
+		b, _ := hex.DecodeString(data)
+		var v interface{}
+		if err := cbor.Unmarshal(b, &v); err == nil {
+			t.Errorf("Unmarshal(%s) error = nil", data)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dt := time.Date(2018, 10, 16, 9, 58, 3, 487508407, time.UTC)
+	req := &sedoc.Request{
+		ID:        "42",
+		Datetime:  dt,
+		Command:   "user.update",
+		Arguments: sedoc.InterfaceMap{"uuid": "01234567-89ab-cdef-0123-456789abcdef", "at": dt},
+		Set:       sedoc.InterfaceMap{"avatar": []byte{0xff, 0}, "age": int64(-3), "nick": nil},
+	}
+	b, err := cbor.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &sedoc.Request{}
+	if err = cbor.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, req) {
+		t.Errorf("request = %+v, want %+v", got, req)
+	}
+
+	resp := &sedoc.Response{
+		ID:     "42",
+		Result: map[string]interface{}{"ok": true, "score": 0.5},
+		Meta:   &sedoc.Meta{Duration: types.Duration(time.Millisecond), Warnings: []string{"deprecated"}},
+	}
+	if b, err = cbor.Marshal(resp); err != nil {
+		t.Fatal(err)
+	}
+	gotResp := &sedoc.Response{}
+	if err = cbor.Unmarshal(b, gotResp); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotResp, resp) {
+		t.Errorf("response = %+v, want %+v", gotResp, resp)
+	}
+}
@@ -0,0 +1,338 @@
+package sedoc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nsemikov/go-sedoc/cbor"
+	"github.com/nsemikov/go-sedoc/msgpack"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Codec is wire format of Request and Response usable by any transport
+type Codec interface {
+	// Name is short format name like "json", it is key of Example encodings
+	Name() string
+	// MIMEType is media type like "application/json" used for negotiation
+	MIMEType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// ExampleRenderer is optional interface of Codec which renders Example
+// human readable. Codec without it is rendered by Marshal: as is if output
+// is text or hex encoded otherwise
+type ExampleRenderer interface {
+	RenderExample(v interface{}) (string, error)
+}
+
+// NewCodec return Codec by marshal and unmarshal functions
+func NewCodec(name, mimeType string, marshal func(interface{}) ([]byte, error), unmarshal func([]byte, interface{}) error) Codec {
+	return codecFuncs{name: name, mimeType: mimeType, marshal: marshal, unmarshal: unmarshal}
+}
+
+type codecFuncs struct {
+	name      string
+	mimeType  string
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+	render    func(interface{}) (string, error)
+}
+
+func (c codecFuncs) Name() string                               { return c.name }
+func (c codecFuncs) MIMEType() string                           { return c.mimeType }
+func (c codecFuncs) Marshal(v interface{}) ([]byte, error)      { return c.marshal(v) }
+func (c codecFuncs) Unmarshal(data []byte, v interface{}) error { return c.unmarshal(data, v) }
+
+func (c codecFuncs) RenderExample(v interface{}) (string, error) {
+	if c.render != nil {
+		return c.render(v)
+	}
+	b, err := c.marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if utf8.Valid(b) {
+		return string(b), nil
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Builtin codecs. MessagePack and CBOR examples are rendered hex encoded
+var (
+	JSONCodec Codec = codecFuncs{name: "json", mimeType: "application/json", marshal: json.Marshal, unmarshal: json.Unmarshal,
+		render: func(v interface{}) (string, error) {
+			b, err := json.MarshalIndent(v, "", "    ")
+			return string(b), err
+		}}
+	XMLCodec Codec = codecFuncs{name: "xml", mimeType: "application/xml", marshal: xml.Marshal, unmarshal: xml.Unmarshal,
+		render: func(v interface{}) (string, error) {
+			b, err := xml.MarshalIndent(v, "", "    ")
+			return xml.Header + string(b), err
+		}}
+	YAMLCodec    Codec = codecFuncs{name: "yaml", mimeType: "application/yaml", marshal: yaml.Marshal, unmarshal: yaml.Unmarshal}
+	MsgPackCodec Codec = codecFuncs{name: "msgpack", mimeType: "application/msgpack", marshal: msgpack.Marshal, unmarshal: msgpack.Unmarshal,
+		render: hexRender(msgpack.Marshal)}
+	CBORCodec Codec = codecFuncs{name: "cbor", mimeType: "application/cbor", marshal: cbor.Marshal, unmarshal: cbor.Unmarshal,
+		render: hexRender(cbor.Marshal)}
+)
+
+// DefaultCodecs is list of builtin Codec
+var DefaultCodecs = []Codec{JSONCodec, XMLCodec, YAMLCodec, MsgPackCodec, CBORCodec}
+
+func hexRender(marshal func(interface{}) ([]byte, error)) func(interface{}) (string, error) {
+	return func(v interface{}) (string, error) {
+		b, err := marshal(v)
+		return hex.EncodeToString(b), err
+	}
+}
+
+// RenderExample render v by Codec ExampleRenderer (or Marshal)
+func RenderExample(c Codec, v interface{}) (string, error) {
+	if r, ok := c.(ExampleRenderer); ok {
+		return r.RenderExample(v)
+	}
+	return codecFuncs{marshal: c.Marshal}.RenderExample(v)
+}
+
+// CodecInfo describes Codec registered in API
+type CodecInfo struct {
+	XMLName  xml.Name `json:"-" xml:"codec" yaml:"-"`
+	Name     string   `json:"name" xml:"name,attr" yaml:"name"`
+	MIMEType string   `json:"mime_type" xml:"mime_type,attr" yaml:"mime_type"`
+}
+
+// Codecs is registry of Codec. First registered Codec is default one.
+// It is safe for concurrent use
+type Codecs struct {
+	mu     sync.RWMutex
+	codecs []Codec
+}
+
+// NewCodecs is Codecs constructor
+func NewCodecs(codecs ...Codec) *Codecs {
+	r := &Codecs{}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// Register add Codec to registry. Register will rewrite Codec with the same Name
+func (r *Codecs) Register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for idx := range r.codecs {
+		if r.codecs[idx].Name() == c.Name() {
+			r.codecs[idx] = c
+			return
+		}
+	}
+	r.codecs = append(r.codecs, c)
+}
+
+// Get return Codec by Name
+func (r *Codecs) Get(name string) (Codec, bool) {
+	for _, c := range r.List() {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// List return copy of registered Codec list
+func (r *Codecs) List() []Codec {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Codec{}, r.codecs...)
+}
+
+// ForContentType return Codec of Content-Type header value (parameters are ignored)
+func (r *Codecs) ForContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	for _, c := range r.List() {
+		if strings.EqualFold(c.MIMEType(), mediaType) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate return Codec for Accept header value like
+// "application/cbor, application/json;q=0.5". Quality of Codec is quality
+// of the most specific matching media range, so q=0 excludes Codec even if
+// wildcard matches it (RFC 9110 section 12.5.1). Empty accept gives default Codec
+func (r *Codecs) Negotiate(accept string) (Codec, bool) {
+	codecs := r.List()
+	if len(codecs) == 0 {
+		return nil, false
+	}
+	if len(strings.TrimSpace(accept)) == 0 {
+		return codecs[0], true
+	}
+	type mediaRange struct {
+		mediaType string
+		q         float64
+		order     int
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType, q, len(ranges)})
+	}
+	var best *mediaRange
+	var res Codec
+	for _, c := range codecs {
+		var matched *mediaRange
+		for idx := range ranges {
+			rng := &ranges[idx]
+			if matchMediaRange(rng.mediaType, c.MIMEType()) &&
+				(matched == nil || mediaRangeSpecificity(rng.mediaType) > mediaRangeSpecificity(matched.mediaType)) {
+				matched = rng
+			}
+		}
+		if matched == nil || matched.q <= 0 {
+			continue
+		}
+		if best == nil || matched.q > best.q || (matched.q == best.q && matched.order < best.order) {
+			best, res = matched, c
+		}
+	}
+	return res, res != nil
+}
+
+// mediaRangeSpecificity return 0 for "*/*", 1 for "type/*" and 2 for media type
+func mediaRangeSpecificity(rng string) int {
+	switch {
+	case rng == "*/*":
+		return 0
+	case strings.HasSuffix(rng, "/*"):
+		return 1
+	}
+	return 2
+}
+
+func matchMediaRange(rng, mediaType string) bool {
+	if rng == "*/*" || strings.EqualFold(rng, mediaType) {
+		return true
+	}
+	typ, sub, _ := strings.Cut(rng, "/")
+	mtyp, _, _ := strings.Cut(mediaType, "/")
+	return sub == "*" && strings.EqualFold(typ, mtyp)
+}
+
+// Render return v rendered by each registered Codec
+func (r *Codecs) Render(v interface{}) Encodings {
+	res := Encodings{}
+	for _, c := range r.List() {
+		if s, err := RenderExample(c, v); err == nil {
+			res[c.Name()] = s
+		}
+	}
+	return res
+}
+
+// Infos return CodecInfo of registered codecs
+func (r *Codecs) Infos() []CodecInfo {
+	var res []CodecInfo
+	for _, c := range r.List() {
+		res = append(res, CodecInfo{Name: c.Name(), MIMEType: c.MIMEType()})
+	}
+	return res
+}
+
+// MarshalJSON for marshal into JSON
+func (r *Codecs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Infos())
+}
+
+// MarshalYAML for marshal into YAML
+func (r *Codecs) MarshalYAML() (interface{}, error) {
+	return r.Infos(), nil
+}
+
+// MarshalXML for marshal into XML
+func (r *Codecs) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	a := []interface{}{}
+	for _, item := range r.Infos() {
+		a = append(a, item)
+	}
+	return MarshallerXML(a)(e, start)
+}
+
+// RegisterCodec add Codec to API Codecs
+func (api *API) RegisterCodec(c Codec) {
+	if api.Codecs == nil {
+		api.Codecs = NewCodecs()
+	}
+	api.Codecs.Register(c)
+}
+
+// DecodeRequest decode request body of contentType by registered Codec
+func (api *API) DecodeRequest(contentType string, body []byte) (*Request, error) {
+	c, ok := api.Codecs.ForContentType(contentType)
+	if !ok {
+		return nil, fmt.Errorf("sedoc: unsupported content type: %s", contentType)
+	}
+	request := NewRequest()
+	if err := c.Unmarshal(body, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// EncodeResponse encode response by Codec negotiated for accept, return body and its content type
+func (api *API) EncodeResponse(accept string, response *Response) ([]byte, string, error) {
+	c, ok := api.Codecs.Negotiate(accept)
+	if !ok {
+		return nil, "", fmt.Errorf("sedoc: not acceptable: %s", accept)
+	}
+	b, err := c.Marshal(response)
+	return b, c.MIMEType(), err
+}
+
+// Encodings is map of rendered Example keyed by Codec Name
+type Encodings map[string]string
+
+// MarshalXML marshals Encodings into XML as list of encoding elements
+func (enc Encodings) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type encoding struct {
+		XMLName xml.Name `xml:"encoding"`
+		Codec   string   `xml:"codec,attr"`
+		Value   string   `xml:",chardata"`
+	}
+	keys := make([]string, 0, len(enc))
+	for key := range enc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	a := []interface{}{}
+	for _, key := range keys {
+		a = append(a, encoding{Codec: key, Value: enc[key]})
+	}
+	return MarshallerXML(a)(e, start)
+}
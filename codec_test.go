@@ -0,0 +1,126 @@
+package sedoc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCodecs(t *testing.T) {
+	names := []string{"json", "xml", "yaml", "msgpack", "cbor"}
+	for idx, c := range DefaultCodecs {
+		if c.Name() != names[idx] {
+			t.Errorf("codec %d name = %s, want %s", idx, c.Name(), names[idx])
+		}
+		b, err := c.Marshal(&Request{ID: "42", Command: "help"})
+		if err != nil {
+			t.Fatalf("%s: Marshal() error = %v", c.Name(), err)
+		}
+		got := &Request{}
+		if err = c.Unmarshal(b, got); err != nil || got.ID != "42" || got.Command != "help" {
+			t.Errorf("%s: Unmarshal() = %+v, %v", c.Name(), got, err)
+		}
+	}
+}
+
+func TestAPI_Execute_helpBinaryExamples(t *testing.T) {
+	a := New()
+	a.Execute(&Request{Command: "help"})
+	example := a.GetCommand("help").Examples[0].Request
+	for _, tt := range []struct {
+		codec Codec
+		s     string
+	}{{MsgPackCodec, example.Encodings["msgpack"]}, {CBORCodec, example.Encodings["cbor"]}} {
+		b, err := hex.DecodeString(tt.s)
+		if err != nil || len(b) == 0 {
+			t.Fatalf("%s example = %q", tt.codec.Name(), tt.s)
+		}
+		got := &Request{}
+		if err = tt.codec.Unmarshal(b, got); err != nil || got.Command != "help" || !got.Datetime.Equal(example.Object.Datetime) {
+			t.Errorf("%s example decoded = %+v, %v", tt.codec.Name(), got, err)
+		}
+	}
+}
+
+func TestCodecs_Negotiate(t *testing.T) {
+	r := NewCodecs(DefaultCodecs...)
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "json"},
+		{"application/cbor", "cbor"},
+		{"application/xml;q=0.5, application/msgpack", "msgpack"},
+		{"text/html, application/*;q=0.1", "json"},
+		{"*/*", "json"},
+		{"application/yaml;q=0, application/xml;q=0.2", "xml"},
+		{"text/html", ""},
+		{"application/json;q=0", ""},
+		{"application/json;q=0, */*", "xml"},
+		{"application/*;q=0, */*", ""},
+		{"*/*;q=0.1, application/cbor;q=0.5, application/json;q=0", "cbor"},
+		{"application/cbor, application/json", "cbor"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if c, ok := r.Negotiate(tt.accept); ok {
+			got = c.Name()
+		}
+		if got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+	if c, ok := r.ForContentType("application/json; charset=utf-8"); !ok || c.Name() != "json" {
+		t.Errorf("ForContentType() = %v, %v", c, ok)
+	}
+	if _, ok := r.ForContentType("text/plain"); ok {
+		t.Error("ForContentType() of unknown type = true")
+	}
+}
+
+func TestAPI_RegisterCodec(t *testing.T) {
+	a := New()
+	a.RegisterCodec(NewCodec("upper", "text/x-upper",
+		func(v interface{}) ([]byte, error) {
+			b, err := json.Marshal(v)
+			return []byte(strings.ToUpper(string(b))), err
+		},
+		func(data []byte, v interface{}) error {
+			return json.Unmarshal([]byte(strings.ToLower(string(data))), v)
+		}))
+	req, err := a.DecodeRequest("text/x-upper", []byte(`{"COMMAND":"HELP"}`))
+	if err != nil || req.Command != "help" {
+		t.Fatalf("DecodeRequest() = %v, %v", req, err)
+	}
+	if _, err = a.DecodeRequest("text/plain", nil); err == nil {
+		t.Error("DecodeRequest() of unsupported type error = nil")
+	}
+	resp := a.Execute(req)
+	example := a.GetCommand("help").Examples[0].Request
+	if len(example.Encodings) != len(DefaultCodecs)+1 || !strings.Contains(example.Encodings["upper"], `"COMMAND":"HELP"`) {
+		t.Errorf("Encodings = %v", example.Encodings)
+	}
+	if example.Encodings["json"] != example.JSONString() || !strings.HasPrefix(example.Encodings["xml"], xml.Header) {
+		t.Errorf("Encodings = %v", example.Encodings)
+	}
+	b, contentType, err := a.EncodeResponse("text/x-upper", &Response{Command: resp.Command})
+	if err != nil || contentType != "text/x-upper" || !strings.Contains(string(b), `"COMMAND":"HELP"`) {
+		t.Errorf("EncodeResponse() = %s, %s, %v", b, contentType, err)
+	}
+	if _, _, err = a.EncodeResponse("text/html", resp); err == nil {
+		t.Error("EncodeResponse() of not acceptable error = nil")
+	}
+}
+
+func TestEncodings_MarshalXML(t *testing.T) {
+	b, err := xml.Marshal(ExampleRequest{Encodings: Encodings{"yaml": "command: help\n", "json": "{}"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<encodings count="2"><encoding codec="json">{}</encoding><encoding codec="yaml">command: help&#xA;</encoding></encodings>`
+	if !strings.Contains(string(b), want) {
+		t.Errorf("xml = %s, want %s", b, want)
+	}
+}
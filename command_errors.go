@@ -0,0 +1,93 @@
+package sedoc
+
+import "sort"
+
+// apiErrors is codes of errors returned by API wide settings and middleware
+// (API Concurrency, RateLimitConfig Global and Identity, CheckResult). They
+// are listed in API Errors, but not in Command Errors unless implied by
+// Command itself, CheckErrors does not report them
+var apiErrors = []int{ErrInvalidResult, ErrRateLimited, ErrOverloaded}
+
+// impliedErrors return codes of errors which API itself may return for Command:
+// validation errors of its arguments and errors of its Kind and limits.
+// Codes of API wide settings (see apiErrors) are not implied
+func (cmd *Command) impliedErrors() []int {
+	codes := []int{ErrUnknown, ErrUnknownArgument}
+	all := append(append(append(Arguments{}, cmd.Arguments...), cmd.Where...), cmd.Set...)
+	if len(all) > 0 {
+		codes = append(codes, ErrInvalidArgumentValue)
+	}
+	for _, arg := range all {
+		if arg.Required && !arg.Disabled {
+			codes = append(codes, ErrRequiredArgumentMissing)
+		}
+		if len(arg.RegExp) > 0 {
+			codes = append(codes, ErrInvalidArgumentRegExp, ErrArgumentRegExpMatchFails)
+		}
+	}
+	if len(cmd.Where) > 0 {
+		codes = append(codes, ErrInvalidWhereOperator)
+	}
+	if cmd.Kind == CommandKindSubscription {
+		codes = append(codes, ErrStreamRequired)
+	}
+	if cmd.Kind == CommandKindAsync {
+		codes = append(codes, ErrOverloaded)
+	}
+	if cmd.RateLimit != nil {
+		codes = append(codes, ErrRateLimited)
+	}
+	if cmd.Concurrency != nil {
+		codes = append(codes, ErrOverloaded)
+	}
+	return codes
+}
+
+// withImpliedErrors return sorted unique codes of declared Errors and implied ones
+func (cmd *Command) withImpliedErrors() []int {
+	seen := map[int]bool{}
+	var codes []int
+	for _, code := range append(append([]int{}, cmd.Errors...), cmd.impliedErrors()...) {
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+// DeclaresError return true if code is in Command Errors
+func (cmd *Command) DeclaresError(code int) bool {
+	return containsInt(cmd.Errors, code)
+}
+
+// CheckErrors is middleware which checks that error code returned by handler
+// is declared in Command Errors (or is one of API wide codes, which depend on
+// API settings). It is intended for debug mode: undeclared code is reported
+// by Response warning
+func CheckErrors(next HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		err := next(c)
+		if err == nil {
+			return nil
+		}
+		code := ErrUnknown
+		if serr, ok := asError(err); ok {
+			code = serr.Code
+		}
+		if !c.Command().DeclaresError(code) && !containsInt(apiErrors, code) {
+			c.Warn("command %s returned undeclared error code %d", c.Command().Name, code)
+		}
+		return err
+	}
+}
+
+func containsInt(arr []int, i int) bool {
+	for _, item := range arr {
+		if item == i {
+			return true
+		}
+	}
+	return false
+}
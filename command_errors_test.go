@@ -0,0 +1,85 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPI_AddCommand_errors(t *testing.T) {
+	a := New()
+	a.AddCommand(Command{Name: "ping", Handler: func(c Context) error { return nil }})
+	a.AddCommand(Command{
+		Name:      "user.list",
+		Handler:   func(c Context) error { return nil },
+		Errors:    []int{1001, ErrUnknown},
+		Arguments: Arguments{{Name: "login", Type: ArgumentTypeString, RegExp: "^[a-z]+$"}},
+		Where:     Arguments{{Name: "id", Type: ArgumentTypeInteger, Required: true}},
+		RateLimit: &Rate{Requests: 1, Per: 1},
+	})
+	a.AddCommand(Command{Name: "events", Kind: CommandKindSubscription, Handler: func(c Context) error { return nil }})
+	tests := []struct {
+		name string
+		want []int
+	}{
+		{"ping", []int{ErrUnknown, ErrUnknownArgument}},
+		{"user.list", []int{ErrUnknown, ErrInvalidArgumentRegExp, ErrArgumentRegExpMatchFails, ErrRequiredArgumentMissing,
+			ErrUnknownArgument, ErrInvalidArgumentValue, ErrInvalidWhereOperator, ErrRateLimited, 1001}},
+		{"events", []int{ErrUnknown, ErrUnknownArgument, ErrStreamRequired}},
+		{CommandUnsubscribe, []int{ErrUnknown, ErrRequiredArgumentMissing, ErrUnknownArgument, ErrInvalidArgumentValue}},
+	}
+	for _, tt := range tests {
+		if got := a.GetCommand(tt.name).Errors; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s Errors = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	b, _ := json.Marshal(a.GetCommand("ping"))
+	if !strings.Contains(string(b), `"errors":[1,7]`) {
+		t.Errorf("json = %s", b)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	a := New()
+	a.Use(CheckErrors)
+	a.AddCommand(Command{
+		Name:      "user.get",
+		Errors:    []int{1001},
+		Arguments: Arguments{{Name: "code", Type: ArgumentTypeInteger}},
+		Handler: func(c Context) error {
+			switch code := c.Request().Arguments["code"].(int); code {
+			case 0:
+				return nil
+			case -1:
+				return errors.New("plain")
+			default:
+				return c.Error(code)
+			}
+		},
+	})
+	tests := []struct {
+		code    int
+		warning string
+	}{
+		{0, ""},
+		{1001, ""},
+		{ErrInvalidArgumentValue, ""},
+		{1002, "command user.get returned undeclared error code 1002"},
+		{ErrUnknownJob, "command user.get returned undeclared error code 12"},
+		{ErrRateLimited, ""},
+		{ErrOverloaded, ""},
+		{-1, ""},
+	}
+	for _, tt := range tests {
+		resp := a.Execute(&Request{Command: "user.get", Arguments: InterfaceMap{"code": tt.code}})
+		var warnings []string
+		if resp.Meta != nil {
+			warnings = resp.Meta.Warnings
+		}
+		if (len(tt.warning) == 0) != (len(warnings) == 0) || (len(warnings) > 0 && warnings[0] != tt.warning) {
+			t.Errorf("code %d: warnings = %v, want %q", tt.code, warnings, tt.warning)
+		}
+	}
+}
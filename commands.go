@@ -5,16 +5,39 @@ import (
 	"fmt"
 )
 
+// CommandKind is enum of Command execution kinds
+type CommandKind string
+
+const (
+	// CommandKindCall is default kind: single Response for Request
+	CommandKindCall CommandKind = ""
+	// CommandKindSubscription means Command pushes Events (tagged with
+	// Response Subscription) by streaming transport until client unsubscribes
+	CommandKindSubscription CommandKind = "subscription"
+	// CommandKindAsync means Command Handler runs in background by API Jobs,
+	// Response Result is Job which is polled by job.status and job.result
+	CommandKindAsync CommandKind = "async"
+)
+
 // Command is api command
 type Command struct {
-	XMLName     xml.Name    `json:"-" xml:"command" yaml:"-"`
-	Name        string      `json:"name" xml:"name,attr" yaml:"name"`
-	Description string      `json:"description" xml:"description,attr" yaml:"description"`
-	Arguments   Arguments   `json:"args,omitempty" xml:"args,omitempty" yaml:"args,omitempty"`
-	Where       Arguments   `json:"where,omitempty" xml:"where,omitempty" yaml:"where,omitempty"`
-	Set         Arguments   `json:"set,omitempty" xml:"set,omitempty" yaml:"set,omitempty"`
-	Handler     HandlerFunc `json:"-" xml:"-" yaml:"-"`
-	Examples    Examples    `json:"examples,omitempty" xml:"examples,omitempty" yaml:"examples,omitempty"`
+	XMLName     xml.Name          `json:"-" xml:"command" yaml:"-"`
+	Name        string            `json:"name" xml:"name,attr" yaml:"name"`
+	Description string            `json:"description" xml:"description,attr" yaml:"description"`
+	Kind        CommandKind       `json:"kind,omitempty" xml:"kind,attr,omitempty" yaml:"kind,omitempty"`
+	Arguments   Arguments         `json:"args,omitempty" xml:"args,omitempty" yaml:"args,omitempty"`
+	Where       Arguments         `json:"where,omitempty" xml:"where,omitempty" yaml:"where,omitempty"`
+	Set         Arguments         `json:"set,omitempty" xml:"set,omitempty" yaml:"set,omitempty"`
+	Result      Arguments         `json:"result,omitempty" xml:"result,omitempty" yaml:"result,omitempty"`
+	Events      Arguments         `json:"events,omitempty" xml:"events,omitempty" yaml:"events,omitempty"`
+	Paging      *Paging           `json:"paging,omitempty" xml:"paging,omitempty" yaml:"paging,omitempty"`
+	Sort        []string          `json:"sort,omitempty" xml:"sort>field,omitempty" yaml:"sort,omitempty"`
+	Fields      []string          `json:"fields,omitempty" xml:"fields>field,omitempty" yaml:"fields,omitempty"`
+	RateLimit   *Rate             `json:"rate_limit,omitempty" xml:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	Concurrency *ConcurrencyLimit `json:"concurrency,omitempty" xml:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+	Errors      []int             `json:"errors,omitempty" xml:"errors>code,omitempty" yaml:"errors,omitempty"`
+	Handler     HandlerFunc       `json:"-" xml:"-" yaml:"-"`
+	Examples    Examples          `json:"examples,omitempty" xml:"examples,omitempty" yaml:"examples,omitempty"`
 }
 
 // Commands is array of Command
@@ -26,10 +49,24 @@ func (arr *Commands) Contains(name string) bool {
 	return err == nil
 }
 
-// Add is add command to array
+// Add is add command to array. Add panics if command already exist
 func (arr *Commands) Add(cmd Command) error {
+	if err := arr.Append(cmd); err != nil {
+		panic(err.Error())
+	}
+	return nil
+}
+
+// Append is add command to array, return error if command already exist
+// or its RateLimit is invalid
+func (arr *Commands) Append(cmd Command) error {
 	if arr.Contains(cmd.Name) {
-		panic("command already exist: " + cmd.Name)
+		return fmt.Errorf("command already exist: %s", cmd.Name)
+	}
+	if cmd.RateLimit != nil {
+		if err := cmd.RateLimit.Validate(); err != nil {
+			return fmt.Errorf("command %s: %v", cmd.Name, err)
+		}
 	}
 	*arr = append(*arr, cmd)
 	return nil
@@ -71,15 +108,18 @@ func (cmd *Command) Valid() bool {
 	return len(cmd.Name) > 0 && cmd.Handler != nil
 }
 
-func (cmd *Command) checkArguments(request *Request) (err error) {
-	if err = checkArguments(&request.Arguments, cmd.Arguments, "args: "); err != nil {
+func (cmd *Command) checkArguments(request *Request, types *ArgumentTypes) (err error) {
+	if err = checkArguments(&request.Arguments, cmd.withListArguments(), "args: ", types); err != nil {
 		return
 	}
-	if err = checkArguments(&request.Set, cmd.Set, "set: "); err != nil {
+	if err = cmd.checkList(request); err != nil {
+		return
+	}
+	if err = checkArguments(&request.Set, cmd.Set, "set: ", types); err != nil {
 		return
 	}
 	for idx, where := range request.Where {
-		if err = checkArguments(&where, cmd.Where, fmt.Sprintf("where[%d]: ", idx)); err != nil {
+		if err = checkWhere(&where, cmd.Where, fmt.Sprintf("where[%d]: ", idx), types); err != nil {
 			return
 		}
 		request.Where[idx] = where
@@ -87,7 +127,29 @@ func (cmd *Command) checkArguments(request *Request) (err error) {
 	return
 }
 
-func checkArguments(params *InterfaceMap, args Arguments, errPrefix string) (e error) {
+// valueDetail return ErrorDetail of invalid value of Argument
+func (arg Argument) valueDetail(errPrefix, field, reason string, actual interface{}) ErrorDetail {
+	detail := fieldDetail(errPrefix, field, reason)
+	detail.Expected = string(arg.Type)
+	if arg.Multiple {
+		detail.Expected = "[]" + detail.Expected
+	}
+	detail.Actual = arg.actual(actual)
+	return detail
+}
+
+// actual return invalid value of Argument for ErrorDetail, value of Sensitive Argument is redacted
+func (arg Argument) actual(val interface{}) string {
+	switch {
+	case arg.Sensitive:
+		return RedactedValue
+	case val == nil:
+		return "null"
+	}
+	return fmt.Sprint(val)
+}
+
+func checkArguments(params *InterfaceMap, args Arguments, errPrefix string, types *ArgumentTypes) (e error) {
 	var err = &Error{}
 	for _, arg := range args {
 		if arg.Disabled {
@@ -97,6 +159,7 @@ func checkArguments(params *InterfaceMap, args Arguments, errPrefix string) (e e
 		if arg.Required && !ok {
 			*err = DefaultErrors.Get(ErrRequiredArgumentMissing)
 			err.Description = fmt.Sprintf("%s%s (%s)", errPrefix, err.Description, arg.Name)
+			err.Details = []ErrorDetail{fieldDetail(errPrefix, arg.Name, "required")}
 			return err
 		}
 	}
@@ -105,30 +168,39 @@ func checkArguments(params *InterfaceMap, args Arguments, errPrefix string) (e e
 		if gerr != nil {
 			*err = DefaultErrors.Get(ErrUnknownArgument)
 			err.Description = fmt.Sprintf("%s%s (%s)", errPrefix, err.Description, name)
+			err.Details = []ErrorDetail{fieldDetail(errPrefix, name, "unknown")}
 			return err
 		}
 		if val == nil {
 			if !arg.Nullable {
 				*err = DefaultErrors.Get(ErrInvalidArgumentValue)
 				err.Description = fmt.Sprintf("%s%s (%s): %v", errPrefix, err.Description, name, "null")
+				err.Details = []ErrorDetail{arg.valueDetail(errPrefix, name, "not nullable", nil)}
 				return err
 			}
-		} else if val, e = arg.Type.Parse(val, arg.Multiple); e != nil {
+		} else if parsed, e := types.Parse(arg.Type, val, arg.Multiple); e != nil {
 			*err = DefaultErrors.Get(ErrInvalidArgumentValue)
-			err.Description = fmt.Sprintf("%s%s (%s): %v", errPrefix, err.Description, name, val)
+			err.Description = fmt.Sprintf("%s%s (%s): %v", errPrefix, err.Description, name, parsed)
+			err.Details = []ErrorDetail{arg.valueDetail(errPrefix, name, "invalid type", val)}
 			err.Internal = e
 			return err
+		} else {
+			val = parsed
 		}
 		if len(arg.RegExp) > 0 {
 			ok, e := arg.Match(val)
 			if e != nil {
 				*err = DefaultErrors.Get(ErrInvalidArgumentRegExp)
 				err.Description = fmt.Sprintf("%s%s (%s)", errPrefix, err.Description, name)
+				err.Details = []ErrorDetail{fieldDetail(errPrefix, name, "invalid regexp")}
 				return err
 			}
 			if !ok {
 				*err = DefaultErrors.Get(ErrArgumentRegExpMatchFails)
 				err.Description = fmt.Sprintf("%s%s (%s, regexp: %s)", errPrefix, err.Description, name, arg.RegExp)
+				detail := fieldDetail(errPrefix, name, "regexp mismatch")
+				detail.Expected, detail.Actual = arg.RegExp, arg.actual(val)
+				err.Details = []ErrorDetail{detail}
 				return err
 			}
 		}
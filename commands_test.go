@@ -85,7 +85,7 @@ func TestCommand_checkArguments(t *testing.T) {
 				Handler:     tt.fields.Handler,
 				Examples:    tt.fields.Examples,
 			}
-			if err := cmd.checkArguments(tt.args.request); (err != nil) != tt.wantErr {
+			if err := cmd.checkArguments(tt.args.request, nil); (err != nil) != tt.wantErr {
 				t.Errorf("Command.checkArguments() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,174 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"encoding/xml"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+// ConcurrencyLimit limits count of in-flight executions: at most Max run at
+// once, at most Queue wait for free slot no longer than Timeout (or context
+// of execution deadline). Other executions fail fast with ErrOverloaded
+type ConcurrencyLimit struct {
+	XMLName xml.Name       `json:"-" xml:"concurrency" yaml:"-"`
+	Max     int            `json:"max" xml:"max,attr" yaml:"max"`
+	Queue   int            `json:"queue,omitempty" xml:"queue,attr,omitempty" yaml:"queue,omitempty"`
+	Timeout types.Duration `json:"timeout,omitempty" xml:"timeout,attr,omitempty" yaml:"timeout,omitempty"`
+}
+
+// ConcurrencyStats is state of concurrency limiter
+type ConcurrencyStats struct {
+	InFlight int64 `json:"in_flight" xml:"in_flight,attr" yaml:"in_flight"`
+	Queued   int64 `json:"queued" xml:"queued,attr" yaml:"queued"`
+	Shed     int64 `json:"shed" xml:"shed,attr" yaml:"shed"`
+}
+
+// Stats is API runtime statistics
+type Stats struct {
+	Concurrency         ConcurrencyStats            `json:"concurrency" xml:"concurrency" yaml:"concurrency"`
+	CommandsConcurrency map[string]ConcurrencyStats `json:"commands_concurrency,omitempty" xml:"-" yaml:"commands_concurrency,omitempty"`
+}
+
+type limiter struct {
+	limit    ConcurrencyLimit
+	slots    chan struct{}
+	inFlight int64
+	queued   int64
+	shed     int64
+}
+
+func newLimiter(limit ConcurrencyLimit) *limiter {
+	return &limiter{limit: limit, slots: make(chan struct{}, limit.Max)}
+}
+
+// acquire slot, return false if execution is shed
+func (l *limiter) acquire(ctx stdcontext.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		atomic.AddInt64(&l.inFlight, 1)
+		return true
+	default:
+	}
+	if atomic.AddInt64(&l.queued, 1) > int64(l.limit.Queue) {
+		atomic.AddInt64(&l.queued, -1)
+		atomic.AddInt64(&l.shed, 1)
+		return false
+	}
+	defer atomic.AddInt64(&l.queued, -1)
+	var timeout <-chan time.Time
+	if l.limit.Timeout > 0 {
+		timer := time.NewTimer(time.Duration(l.limit.Timeout))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		atomic.AddInt64(&l.inFlight, 1)
+		return true
+	case <-timeout:
+	case <-ctx.Done():
+	}
+	atomic.AddInt64(&l.shed, 1)
+	return false
+}
+
+func (l *limiter) release() {
+	atomic.AddInt64(&l.inFlight, -1)
+	<-l.slots
+}
+
+func (l *limiter) stats() ConcurrencyStats {
+	return ConcurrencyStats{
+		InFlight: atomic.LoadInt64(&l.inFlight),
+		Queued:   atomic.LoadInt64(&l.queued),
+		Shed:     atomic.LoadInt64(&l.shed),
+	}
+}
+
+// limiters is set of API concurrency limiters created on first use and
+// recreated when limit changes. Executions holding slot of replaced limiter
+// release it there
+type limiters struct {
+	mu       sync.Mutex
+	global   *limiter
+	commands map[string]*limiter
+}
+
+func (ls *limiters) get(name string, limit *ConcurrencyLimit) *limiter {
+	if limit == nil || limit.Max <= 0 {
+		return nil
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if len(name) == 0 {
+		if ls.global == nil || ls.global.limit != *limit {
+			ls.global = newLimiter(*limit)
+		}
+		return ls.global
+	}
+	if l := ls.commands[name]; l == nil || l.limit != *limit {
+		ls.commands[name] = newLimiter(*limit)
+	}
+	return ls.commands[name]
+}
+
+// remove limiter of Command
+func (ls *limiters) remove(name string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	delete(ls.commands, name)
+}
+
+// acquire global and Command slots for execution of c. Subscriptions are
+// limited by Command Concurrency only. Returned func release slots
+func (api *API) acquire(c *context) (func(), error) {
+	if api.limiters == nil {
+		return func() {}, nil
+	}
+	cmd := c.Command()
+	global := api.Concurrency
+	if cmd.Kind == CommandKindSubscription {
+		global = nil
+	}
+	var acquired []*limiter
+	release := func() {
+		for _, l := range acquired {
+			l.release()
+		}
+	}
+	for _, l := range []*limiter{api.limiters.get("", global), api.limiters.get(cmd.Name, cmd.Concurrency)} {
+		if l == nil {
+			continue
+		}
+		if !l.acquire(c.StdContext()) {
+			release()
+			return nil, api.NewError(ErrOverloaded)
+		}
+		acquired = append(acquired, l)
+	}
+	return release, nil
+}
+
+// Stats return API runtime statistics
+func (api *API) Stats() Stats {
+	stats := Stats{}
+	if api.limiters == nil {
+		return stats
+	}
+	api.limiters.mu.Lock()
+	defer api.limiters.mu.Unlock()
+	if api.limiters.global != nil {
+		stats.Concurrency = api.limiters.global.stats()
+	}
+	if len(api.limiters.commands) > 0 {
+		stats.CommandsConcurrency = make(map[string]ConcurrencyStats, len(api.limiters.commands))
+		for name, l := range api.limiters.commands {
+			stats.CommandsConcurrency[name] = l.stats()
+		}
+	}
+	return stats
+}
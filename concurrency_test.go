@@ -0,0 +1,115 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"testing"
+	"time"
+
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+func newConcurrencyAPI(release <-chan struct{}, started chan<- struct{}) *API {
+	a := New()
+	a.Concurrency = &ConcurrencyLimit{Max: 2, Queue: 0}
+	a.AddCommand(Command{
+		Name:        "report.build",
+		Concurrency: &ConcurrencyLimit{Max: 1, Queue: 1, Timeout: types.Duration(time.Hour)},
+		Handler: func(c Context) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	})
+	return a
+}
+
+func waitStats(t *testing.T, a *API, cond func(Stats) bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(a.Stats()); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats() = %+v", a.Stats())
+		}
+	}
+}
+
+func TestAPI_concurrency(t *testing.T) {
+	release, started := make(chan struct{}), make(chan struct{}, 10)
+	a := newConcurrencyAPI(release, started)
+	errs := make(chan *Error, 10)
+	exec := func(ctx stdcontext.Context) {
+		errs <- a.ExecuteContext(ctx, &Request{Command: "report.build"}).Error
+	}
+	go exec(stdcontext.Background())
+	<-started
+	go exec(stdcontext.Background())
+	waitStats(t, a, func(s Stats) bool { return s.CommandsConcurrency["report.build"].Queued == 1 })
+
+	// global limit is reached by running and queued executions
+	for _, name := range []string{"report.build", "help"} {
+		if err := a.Execute(&Request{Command: name}).Error; err == nil || err.Code != ErrOverloaded {
+			t.Errorf("Execute(%s) error = %v, want %d", name, err, ErrOverloaded)
+		}
+	}
+	stats := a.Stats()
+	if want := (ConcurrencyStats{InFlight: 2, Queued: 0, Shed: 2}); stats.Concurrency != want {
+		t.Errorf("Stats().Concurrency = %+v, want %+v", stats.Concurrency, want)
+	}
+	if want := (ConcurrencyStats{InFlight: 1, Queued: 1, Shed: 0}); stats.CommandsConcurrency["report.build"] != want {
+		t.Errorf("Stats().CommandsConcurrency = %+v, want %+v", stats.CommandsConcurrency, want)
+	}
+	close(release)
+	for idx := 0; idx < 2; idx++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Execute() error = %v", err)
+		}
+	}
+	waitStats(t, a, func(s Stats) bool { return s.Concurrency.InFlight == 0 })
+}
+
+func TestAPI_concurrencyQueue(t *testing.T) {
+	release, started := make(chan struct{}), make(chan struct{}, 10)
+	defer close(release)
+	a := newConcurrencyAPI(release, started)
+	a.Concurrency = nil
+	go a.Execute(&Request{Command: "report.build"})
+	<-started
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 10*time.Millisecond)
+	defer cancel()
+	// queued execution is shed on context deadline
+	if err := a.ExecuteContext(ctx, &Request{Command: "report.build"}).Error; err == nil || err.Code != ErrOverloaded {
+		t.Errorf("ExecuteContext() error = %v, want %d", err, ErrOverloaded)
+	}
+	go a.Execute(&Request{Command: "report.build"})
+	waitStats(t, a, func(s Stats) bool { return s.CommandsConcurrency["report.build"].Queued == 1 })
+	// queue is full
+	if err := a.Execute(&Request{Command: "report.build"}).Error; err == nil || err.Code != ErrOverloaded {
+		t.Errorf("Execute() error = %v, want %d", err, ErrOverloaded)
+	}
+	if want := (ConcurrencyStats{InFlight: 1, Queued: 1, Shed: 2}); a.Stats().CommandsConcurrency["report.build"] != want {
+		t.Errorf("Stats().CommandsConcurrency = %+v, want %+v", a.Stats().CommandsConcurrency, want)
+	}
+}
+
+func TestAPI_concurrencyChanged(t *testing.T) {
+	release, started := make(chan struct{}), make(chan struct{}, 10)
+	defer close(release)
+	a := newConcurrencyAPI(release, started)
+	a.Concurrency = nil
+	cmd := a.GetCommand("report.build")
+	cmd.Concurrency = &ConcurrencyLimit{Max: 1}
+	a.AddCommand(cmd)
+	go a.Execute(&Request{Command: "report.build"})
+	<-started
+	if err := a.Execute(&Request{Command: "report.build"}).Error; err == nil || err.Code != ErrOverloaded {
+		t.Fatalf("Execute() error = %v, want %d", err, ErrOverloaded)
+	}
+	// limit of re-added Command is applied
+	cmd.Concurrency = &ConcurrencyLimit{Max: 2}
+	a.AddCommand(cmd)
+	go a.Execute(&Request{Command: "report.build"})
+	<-started
+	a.RemoveCommand("report.build")
+	if _, ok := a.Stats().CommandsConcurrency["report.build"]; ok {
+		t.Errorf("Stats() of removed Command = %+v", a.Stats())
+	}
+}
@@ -1,6 +1,7 @@
 package sedoc
 
 import (
+	stdcontext "context"
 	"time"
 )
 
@@ -16,13 +17,37 @@ type Context interface {
 	Error(code int, details ...interface{}) *Error
 	// ErrorInternal method
 	ErrorInternal(code int, internal error, details ...interface{}) *Error
+	// Bind fill tagged struct from request Arguments, Set and Where (equality
+	// conditions of first Where item, operator conditions of bound fields
+	// fail)
+	Bind(dst interface{}) error
+	// Filter return parsed request Where. Error is returned if Where can not be
+	// parsed (it is not validated, e.g. Context is not executed)
+	Filter() (Filter, error)
+	// Page return parsed request paging, sort and fields selection
+	Page() Page
+	// Warn add non-fatal warning to Response Meta
+	Warn(format string, a ...interface{})
+	// Emit write result item into stream (see API ExecuteStream) or append it
+	// to Response Result ([]interface{}, error is returned if Result is set to
+	// other value). Handler should stop on error
+	Emit(v interface{}) error
+	// StdContext return context of execution, it is done when execution is
+	// canceled (client unsubscribed, job canceled etc)
+	StdContext() stdcontext.Context
 }
 
 type context struct {
-	api  *API
-	req  *Request
-	resp *Response
-	cmd  *Command
+	ctx         stdcontext.Context
+	api         *API
+	req         *Request
+	resp        *Response
+	cmd         *Command
+	flt         Filter
+	fltErr      error
+	stream      StreamWriter
+	streamBegun bool
+	streamErr   error
 }
 
 // Request return instance of Request
@@ -41,6 +66,14 @@ func (c *context) Response() *Response {
 	return c.resp
 }
 
+// StdContext return context of execution
+func (c *context) StdContext() stdcontext.Context {
+	if c.ctx == nil {
+		c.ctx = stdcontext.Background()
+	}
+	return c.ctx
+}
+
 // Command return copy of Command
 func (c *context) Command() *Command {
 	if c.cmd == nil {
@@ -63,8 +96,40 @@ func (c context) ErrorInternal(code int, internal error, details ...interface{})
 	return c.api.NewErrorInternal(code, internal, details...)
 }
 
+// Page return parsed request paging, sort and fields selection
+func (c *context) Page() Page {
+	return c.Command().page(c.Request())
+}
+
+// Warn add non-fatal warning to Response Meta
+func (c *context) Warn(format string, a ...interface{}) {
+	c.Response().Warn(format, a...)
+}
+
+// Filter return parsed request Where
+func (c *context) Filter() (Filter, error) {
+	if c.flt == nil && c.fltErr == nil {
+		c.flt, c.fltErr = ParseFilter(c.Request().Where)
+	}
+	return c.flt, c.fltErr
+}
+
 func (c *context) checkRequestArguments() (err error) {
-	return c.Command().checkArguments(c.Request())
+	return c.Command().checkArguments(c.Request(), c.api.Types)
+}
+
+// applyPage fill Response Page from request and project Result to requested fields
+func (c *context) applyPage() (err error) {
+	page := c.Page()
+	resp := c.Response()
+	if c.Command().Paging != nil && resp.Page != nil {
+		resp.Page.Count = page.Count
+		resp.Page.Offset = page.Offset
+	}
+	if len(page.Fields) > 0 {
+		resp.Result, err = Project(resp.Result, page.Fields)
+	}
+	return
 }
 
 func fillResponseMissingDataFromRequest(request *Request, response *Response) {
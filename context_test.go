@@ -92,8 +92,8 @@ func Test_context_Command(t *testing.T) {
 	}{
 		// TODO: Add test cases.
 		{"", fields{nil, nil, nil, nil}, &Command{}, true},
-		{"", fields{a, &Request{Command: "test1"}, nil, nil}, &Command{Name: "test1"}, false},
-		{"", fields{a, &Request{Command: "test2"}, nil, nil}, &Command{Name: "test2", Description: "with desc"}, false},
+		{"", fields{a, &Request{Command: "test1"}, nil, nil}, &Command{Name: "test1", Errors: []int{ErrUnknown, ErrUnknownArgument}}, false},
+		{"", fields{a, &Request{Command: "test2"}, nil, nil}, &Command{Name: "test2", Description: "with desc", Errors: []int{ErrUnknown, ErrUnknownArgument}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
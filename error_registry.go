@@ -0,0 +1,289 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrorNamespaceSedoc is name of ErrorNamespace of builtin errors
+const ErrorNamespaceSedoc = "sedoc"
+
+// ErrorDefinition is Error declared in ErrorNamespace with stable symbolic
+// name. Name and Code must not be changed once released. Zero HTTPStatus
+// and GRPCCode are reported as 500 and gRPC Unknown
+type ErrorDefinition struct {
+	XMLName     xml.Name `json:"-" xml:"error" yaml:"-"`
+	Name        string   `json:"name" xml:"name,attr" yaml:"name"`
+	Code        int      `json:"code" xml:"code,attr" yaml:"code"`
+	Description string   `json:"desc" xml:"desc,attr" yaml:"desc"`
+	HTTPStatus  int      `json:"http_status,omitempty" xml:"http_status,attr,omitempty" yaml:"http_status,omitempty"`
+	GRPCCode    int      `json:"grpc_code,omitempty" xml:"grpc_code,attr,omitempty" yaml:"grpc_code,omitempty"`
+}
+
+// AsError return Error of definition
+func (d ErrorDefinition) AsError() Error {
+	return Error{Code: d.Code, Description: d.Description, HTTPStatus: d.HTTPStatus, GRPCCode: d.GRPCCode}
+}
+
+// ErrorNamespace is range of error codes [First, Last] reserved by module
+type ErrorNamespace struct {
+	XMLName     xml.Name          `json:"-" xml:"namespace" yaml:"-"`
+	Name        string            `json:"name" xml:"name,attr" yaml:"name"`
+	Description string            `json:"description,omitempty" xml:"description,attr,omitempty" yaml:"description,omitempty"`
+	First       int               `json:"first" xml:"first,attr" yaml:"first"`
+	Last        int               `json:"last" xml:"last,attr" yaml:"last"`
+	Errors      []ErrorDefinition `json:"errors,omitempty" xml:"error,omitempty" yaml:"errors,omitempty"`
+}
+
+// NewErrorNamespace is ErrorNamespace constructor
+func NewErrorNamespace(name string, first, last int, description string) *ErrorNamespace {
+	return &ErrorNamespace{Name: name, Description: description, First: first, Last: last}
+}
+
+// Define add error to namespace. Definitions are checked by ErrorRegistry Mount
+func (ns *ErrorNamespace) Define(name string, code int, description string) *ErrorNamespace {
+	return ns.DefineError(ErrorDefinition{Name: name, Code: code, Description: description})
+}
+
+// DefineError add ErrorDefinition to namespace
+func (ns *ErrorNamespace) DefineError(d ErrorDefinition) *ErrorNamespace {
+	ns.Errors = append(ns.Errors, d)
+	return ns
+}
+
+// Contains return true if code is in namespace range
+func (ns *ErrorNamespace) Contains(code int) bool {
+	return code >= ns.First && code <= ns.Last
+}
+
+var errorNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// check return conflicts of namespace itself
+func (ns *ErrorNamespace) check() (conflicts []string) {
+	if !errorNameRegexp.MatchString(ns.Name) {
+		conflicts = append(conflicts, fmt.Sprintf("namespace %q: invalid name", ns.Name))
+	}
+	if ns.First <= 0 || ns.First > ns.Last {
+		conflicts = append(conflicts, fmt.Sprintf("namespace %q: invalid range [%d, %d]", ns.Name, ns.First, ns.Last))
+	}
+	names, codes := map[string]bool{}, map[int]string{}
+	for _, d := range ns.Errors {
+		if !errorNameRegexp.MatchString(d.Name) {
+			conflicts = append(conflicts, fmt.Sprintf("namespace %q: error %q: invalid name", ns.Name, d.Name))
+		} else if names[d.Name] {
+			conflicts = append(conflicts, fmt.Sprintf("namespace %q: error %q: duplicate name", ns.Name, d.Name))
+		}
+		names[d.Name] = true
+		if !ns.Contains(d.Code) {
+			conflicts = append(conflicts, fmt.Sprintf("namespace %q: error %q: code %d out of range [%d, %d]", ns.Name, d.Name, d.Code, ns.First, ns.Last))
+		}
+		if other, ok := codes[d.Code]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("namespace %q: error %q: code %d is used by %q", ns.Name, d.Name, d.Code, other))
+		}
+		codes[d.Code] = d.Name
+	}
+	return
+}
+
+// ErrorConflicts is report of ErrorRegistry Mount conflicts
+type ErrorConflicts []string
+
+// Error convert to string
+func (c ErrorConflicts) Error() string {
+	return "sedoc: error registry conflicts:\n  - " + strings.Join(c, "\n  - ")
+}
+
+// ErrorRegistry is registry of ErrorNamespace. It is safe for concurrent use
+type ErrorRegistry struct {
+	mu         sync.RWMutex
+	namespaces []*ErrorNamespace
+}
+
+// NewErrorRegistry is ErrorRegistry constructor
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{}
+}
+
+// Mount add namespaces to registry. All conflicts (invalid names, overlapping
+// ranges, duplicate codes) are reported by ErrorConflicts, nothing is
+// mounted in that case
+func (r *ErrorRegistry) Mount(namespaces ...*ErrorNamespace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if conflicts := checkNamespaces(append(append([]*ErrorNamespace{}, r.namespaces...), namespaces...), len(r.namespaces)); len(conflicts) > 0 {
+		return conflicts
+	}
+	for _, ns := range namespaces {
+		cp := *ns
+		cp.Errors = append([]ErrorDefinition{}, ns.Errors...)
+		r.namespaces = append(r.namespaces, &cp)
+	}
+	sort.SliceStable(r.namespaces, func(i, j int) bool { return r.namespaces[i].First < r.namespaces[j].First })
+	return nil
+}
+
+// checkNamespaces return conflicts of all namespaces, first mounted are known to be valid
+func checkNamespaces(all []*ErrorNamespace, mounted int) (conflicts ErrorConflicts) {
+	for idx := mounted; idx < len(all); idx++ {
+		ns := all[idx]
+		conflicts = append(conflicts, ns.check()...)
+		for _, other := range all[:idx] {
+			if other.Name == ns.Name {
+				conflicts = append(conflicts, fmt.Sprintf("namespace %q: already mounted", ns.Name))
+			} else if ns.First <= other.Last && other.First <= ns.Last {
+				conflicts = append(conflicts, fmt.Sprintf("namespace %q: range [%d, %d] overlaps namespace %q [%d, %d]",
+					ns.Name, ns.First, ns.Last, other.Name, other.First, other.Last))
+			}
+		}
+	}
+	return
+}
+
+// Namespaces return copy of mounted namespaces ordered by range
+func (r *ErrorRegistry) Namespaces() []ErrorNamespace {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]ErrorNamespace, len(r.namespaces))
+	for idx, ns := range r.namespaces {
+		res[idx] = *ns
+	}
+	return res
+}
+
+// Lookup return ErrorDefinition by qualified name like "sedoc.unknown_command"
+func (r *ErrorRegistry) Lookup(name string) (ErrorDefinition, bool) {
+	nsName, errName, ok := strings.Cut(name, ".")
+	if r == nil || !ok {
+		return ErrorDefinition{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ns := range r.namespaces {
+		if ns.Name != nsName {
+			continue
+		}
+		for _, d := range ns.Errors {
+			if d.Name == errName {
+				return d, true
+			}
+		}
+	}
+	return ErrorDefinition{}, false
+}
+
+// Namespace return name of namespace reserved code
+func (r *ErrorRegistry) Namespace(code int) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ns := range r.namespaces {
+		if ns.Contains(code) {
+			return ns.Name, true
+		}
+	}
+	return "", false
+}
+
+// MarshalJSON for marshal into JSON
+func (r *ErrorRegistry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Namespaces())
+}
+
+// MarshalYAML for marshal into YAML
+func (r *ErrorRegistry) MarshalYAML() (interface{}, error) {
+	return r.Namespaces(), nil
+}
+
+// MarshalXML for marshal into XML
+func (r *ErrorRegistry) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	a := []interface{}{}
+	for _, ns := range r.Namespaces() {
+		a = append(a, ns)
+	}
+	return MarshallerXML(a)(e, start)
+}
+
+// defaultErrorNames is symbolic names of DefaultErrors
+var defaultErrorNames = map[int]string{
+	ErrUnknown:                  "unknown",
+	ErrInvalidRequest:           "invalid_request",
+	ErrUnknownCommand:           "unknown_command",
+	ErrInvalidArgumentRegExp:    "invalid_argument_regexp",
+	ErrArgumentRegExpMatchFails: "argument_regexp_match_fails",
+	ErrRequiredArgumentMissing:  "required_argument_missing",
+	ErrUnknownArgument:          "unknown_argument",
+	ErrInvalidArgumentValue:     "invalid_argument_value",
+	ErrInvalidResult:            "invalid_result",
+	ErrInvalidWhereOperator:     "invalid_where_operator",
+	ErrStreamRequired:           "stream_required",
+	ErrUnknownJob:               "unknown_job",
+	ErrJobNotFinished:           "job_not_finished",
+	ErrJobCanceled:              "job_canceled",
+	ErrIdempotencyConflict:      "idempotency_conflict",
+	ErrRateLimited:              "rate_limited",
+	ErrOverloaded:               "overloaded",
+}
+
+// DefaultErrorNamespace return ErrorNamespace of DefaultErrors, it reserves codes 1..LastUsedErrorCode
+func DefaultErrorNamespace() *ErrorNamespace {
+	ns := NewErrorNamespace(ErrorNamespaceSedoc, 1, LastUsedErrorCode, "Builtin sedoc errors")
+	for _, err := range DefaultErrors {
+		ns.DefineError(ErrorDefinition{
+			Name:        defaultErrorNames[err.Code],
+			Code:        err.Code,
+			Description: err.Description,
+			HTTPStatus:  err.HTTPStatus,
+			GRPCCode:    err.GRPCCode,
+		})
+	}
+	return ns
+}
+
+// MountErrors mount namespaces into API ErrorRegistry and add their errors
+// to API Errors. Codes added to API Errors without namespace are reported as
+// conflicts too. MountErrors is intended to be called at startup
+func (api *API) MountErrors(namespaces ...*ErrorNamespace) error {
+	if api.ErrorRegistry == nil {
+		api.ErrorRegistry = NewErrorRegistry()
+	}
+	var conflicts ErrorConflicts
+	for _, ns := range namespaces {
+		for _, d := range ns.Errors {
+			if api.Errors.Contains(d.Code) {
+				conflicts = append(conflicts, fmt.Sprintf("namespace %q: error %q: code %d is already added to API errors", ns.Name, d.Name, d.Code))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+	if err := api.ErrorRegistry.Mount(namespaces...); err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		for _, d := range ns.Errors {
+			_ = api.Errors.Append(d.AsError())
+		}
+	}
+	return nil
+}
+
+// NewErrorNamed is Error constructor by qualified error name like "billing.card_declined".
+// Unknown name gives ErrUnknown Error
+func (api API) NewErrorNamed(name string, details ...interface{}) *Error {
+	d, ok := api.ErrorRegistry.Lookup(name)
+	if !ok {
+		return api.NewErrorInternal(ErrUnknown, fmt.Errorf("sedoc: unknown error name: %s", name), details...)
+	}
+	return api.NewError(d.Code, details...)
+}
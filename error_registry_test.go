@@ -0,0 +1,108 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestDefaultErrorNamespace_stable guards codes and names of builtin errors, they must not change
+func TestDefaultErrorNamespace_stable(t *testing.T) {
+	want := map[string]int{
+		"unknown": 1, "invalid_request": 2, "unknown_command": 3, "invalid_argument_regexp": 4,
+		"argument_regexp_match_fails": 5, "required_argument_missing": 6, "unknown_argument": 7,
+		"invalid_argument_value": 8, "invalid_result": 9, "invalid_where_operator": 10,
+		"stream_required": 11, "unknown_job": 12, "job_not_finished": 13, "job_canceled": 14,
+		"idempotency_conflict": 15, "rate_limited": 16, "overloaded": 17,
+	}
+	ns := DefaultErrorNamespace()
+	if len(ns.Errors) != len(want) {
+		t.Fatalf("got %d errors, want %d", len(ns.Errors), len(want))
+	}
+	for _, d := range ns.Errors {
+		if want[d.Name] != d.Code {
+			t.Errorf("error %q code = %d, want %d", d.Name, d.Code, want[d.Name])
+		}
+	}
+	if conflicts := ns.check(); len(conflicts) > 0 {
+		t.Errorf("check() = %v", conflicts)
+	}
+}
+
+func TestDefaultErrorNames(t *testing.T) {
+	for _, err := range DefaultErrors {
+		if len(defaultErrorNames[err.Code]) == 0 {
+			t.Errorf("DefaultErrors code %d (%s) has no name", err.Code, err.Description)
+		}
+	}
+}
+
+func TestAPI_MountErrors(t *testing.T) {
+	a := New()
+	billing := NewErrorNamespace("billing", 1000, 1099, "Billing errors").
+		Define("card_declined", 1001, "card declined").
+		Define("insufficient_funds", 1002, "insufficient funds")
+	if err := a.MountErrors(billing); err != nil {
+		t.Fatalf("MountErrors() error = %v", err)
+	}
+	if e := a.NewErrorNamed("billing.card_declined", "visa"); e.Code != 1001 || e.Description != "card declined: visa" {
+		t.Errorf("NewErrorNamed() = %v", e)
+	}
+	if e := a.NewErrorNamed("billing.unknown"); e.Code != ErrUnknown || e.Internal == nil {
+		t.Errorf("NewErrorNamed() of unknown name = %v", e)
+	}
+	if ns, ok := a.ErrorRegistry.Namespace(1050); !ok || ns != "billing" {
+		t.Errorf("Namespace() = %q, %v", ns, ok)
+	}
+	b, err := json.Marshal(a.ErrorRegistry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), `[{"name":"sedoc","description":"Builtin sedoc errors","first":1,"last":100,`) ||
+		!strings.Contains(string(b), `{"name":"billing","description":"Billing errors","first":1000,"last":1099,"errors":[{"name":"card_declined","code":1001,"desc":"card declined"}`) {
+		t.Errorf("json = %s", b)
+	}
+}
+
+func TestAPI_MountErrors_conflicts(t *testing.T) {
+	a := New()
+	a.Errors.Add(Error{Code: 2001, Description: "legacy"})
+	tests := []struct {
+		name string
+		ns   []*ErrorNamespace
+		want []string
+	}{
+		{"overlap", []*ErrorNamespace{NewErrorNamespace("auth", 50, 150, "")},
+			[]string{`namespace "auth": range [50, 150] overlaps namespace "sedoc" [1, 100]`}},
+		{"between", []*ErrorNamespace{NewErrorNamespace("a", 200, 299, ""), NewErrorNamespace("b", 250, 350, "")},
+			[]string{`namespace "b": range [250, 350] overlaps namespace "a" [200, 299]`}},
+		{"duplicate name", []*ErrorNamespace{NewErrorNamespace("sedoc", 200, 299, "")},
+			[]string{`namespace "sedoc": already mounted`}},
+		{"definitions", []*ErrorNamespace{NewErrorNamespace("shop", 300, 399, "").
+			Define("Bad", 301, "").Define("dup", 302, "").Define("dup", 303, "").Define("same", 302, "").Define("out", 400, "")},
+			[]string{
+				`namespace "shop": error "Bad": invalid name`,
+				`namespace "shop": error "dup": duplicate name`,
+				`namespace "shop": error "same": code 302 is used by "dup"`,
+				`namespace "shop": error "out": code 400 out of range [300, 399]`,
+			}},
+		{"legacy", []*ErrorNamespace{NewErrorNamespace("legacy", 2000, 2099, "").Define("legacy", 2001, "")},
+			[]string{`namespace "legacy": error "legacy": code 2001 is already added to API errors`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.MountErrors(tt.ns...)
+			var conflicts ErrorConflicts
+			if !errors.As(err, &conflicts) {
+				t.Fatalf("MountErrors() error = %v", err)
+			}
+			if strings.Join(conflicts, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("conflicts = %q, want %q", conflicts, tt.want)
+			}
+		})
+	}
+	if len(a.ErrorRegistry.Namespaces()) != 1 {
+		t.Errorf("namespaces mounted despite conflicts: %v", a.ErrorRegistry.Namespaces())
+	}
+}
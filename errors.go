@@ -2,7 +2,13 @@ package sedoc
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/nsemikov/go-sedoc/types"
 )
 
 // NewError is Error constructor
@@ -10,25 +16,58 @@ func (api API) NewError(code int, details ...interface{}) *Error {
 	return api.NewErrorInternal(code, nil, details...)
 }
 
-// NewErrorInternal is Error constructor
+// NewErrorInternal is Error constructor. ErrorDetail (or *ErrorDetail) details
+// are added to Error Details, other details are appended to Description
 func (api API) NewErrorInternal(code int, internal error, details ...interface{}) *Error {
 	err := api.Errors.Get(code)
 	err.Internal = internal
-	if len(details) > 0 {
-		err.Description = err.Description + ":"
-		for _, detail := range details {
-			err.Description = err.Description + " " + fmt.Sprint(detail)
+	sep := ":"
+	for _, detail := range details {
+		switch d := detail.(type) {
+		case ErrorDetail:
+			err.Details = append(err.Details, d)
+		case *ErrorDetail:
+			err.Details = append(err.Details, *d)
+		default:
+			err.Description = err.Description + sep + " " + fmt.Sprint(detail)
+			sep = ""
 		}
 	}
 	return &err
 }
 
-// Error is standard quickq error type
+// Error is standard quickq error type. HTTPStatus and GRPCCode are transport
+// status mapping of Code, they are not encoded (see HTTP and GRPC methods)
 type Error struct {
-	XMLName     xml.Name `json:"-" xml:"error" yaml:"-"`
-	Code        int      `json:"code" xml:"code,attr" yaml:"code"`
-	Description string   `json:"desc" xml:"desc,attr" yaml:"desc"`
-	Internal    error    `json:"-" xml:"-" yaml:"-"`
+	XMLName     xml.Name       `json:"-" xml:"error" yaml:"-"`
+	Code        int            `json:"code" xml:"code,attr" yaml:"code"`
+	Description string         `json:"desc" xml:"desc,attr" yaml:"desc"`
+	RetryAfter  types.Duration `json:"retry_after,omitempty" xml:"retry_after,attr,omitempty" yaml:"retry_after,omitempty"`
+	Details     []ErrorDetail  `json:"details,omitempty" xml:"detail,omitempty" yaml:"details,omitempty"`
+	HTTPStatus  int            `json:"-" xml:"-" yaml:"-"`
+	GRPCCode    int            `json:"-" xml:"-" yaml:"-"`
+	Internal    error          `json:"-" xml:"-" yaml:"-"`
+}
+
+// ErrorDetail is structured Error detail. Field is path of request field
+// like "args.login" or "where[0].created[gt]"
+type ErrorDetail struct {
+	XMLName  xml.Name `json:"-" xml:"detail" yaml:"-"`
+	Field    string   `json:"field,omitempty" xml:"field,attr,omitempty" yaml:"field,omitempty"`
+	Reason   string   `json:"reason,omitempty" xml:"reason,attr,omitempty" yaml:"reason,omitempty"`
+	Expected string   `json:"expected,omitempty" xml:"expected,attr,omitempty" yaml:"expected,omitempty"`
+	Actual   string   `json:"actual,omitempty" xml:"actual,attr,omitempty" yaml:"actual,omitempty"`
+}
+
+// fieldDetail return ErrorDetail of field in request section given by error prefix (like "args: ")
+func fieldDetail(errPrefix, field, reason string) ErrorDetail {
+	section := strings.TrimSuffix(errPrefix, ": ")
+	if len(section) > 0 && len(field) > 0 {
+		field = section + "." + field
+	} else if len(section) > 0 {
+		field = section
+	}
+	return ErrorDetail{Field: field, Reason: reason}
 }
 
 // Error convert to string
@@ -39,6 +78,87 @@ func (e Error) Error() string {
 	return fmt.Sprintf("[%d] %s", e.Code, e.Description)
 }
 
+// Unwrap return Internal error
+func (e *Error) Unwrap() error {
+	return e.Internal
+}
+
+// Is report whether target is Error with the same Code
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *Error:
+		return t != nil && t.Code == e.Code
+	case Error:
+		return t.Code == e.Code
+	}
+	return false
+}
+
+// asError return *Error found in err chain by errors.As. Error wrapped by
+// another error is copied with the wrapping error as Internal
+func asError(err error) (*Error, bool) {
+	var serr *Error
+	if !errors.As(err, &serr) {
+		return nil, false
+	}
+	if error(serr) != err {
+		wrapped := *serr
+		wrapped.Internal = err
+		serr = &wrapped
+	}
+	return serr, true
+}
+
+// HTTP return HTTP status of Error (http.StatusInternalServerError if not mapped)
+func (e *Error) HTTP() int {
+	if e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPC return gRPC status code of Error (2, Unknown, if not mapped)
+func (e *Error) GRPC() int {
+	if e.GRPCCode != 0 {
+		return e.GRPCCode
+	}
+	return grpcUnknown
+}
+
+// gRPC status codes used by DefaultErrors
+const (
+	grpcCanceled           = 1
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcNotFound           = 5
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcAborted            = 10
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+)
+
+// PanicError is Internal of ErrUnknown Error for recovered panic
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// recoveredPanic return PanicError of recovered value r with current stack.
+// PanicError re-panicked by middleware (like Logger) is returned as is
+func recoveredPanic(r interface{}) *PanicError {
+	if perr, ok := r.(*PanicError); ok {
+		return perr
+	}
+	return &PanicError{Value: r, Stack: debug.Stack()}
+}
+
+// Error convert to string
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
 const (
 	// ErrUnknown means an unknown error occurred
 	ErrUnknown = iota + 1
@@ -56,20 +176,48 @@ const (
 	ErrUnknownArgument
 	// ErrInvalidArgumentValue means invalid command argument parameter value
 	ErrInvalidArgumentValue
-	// LastUsedErrorCode is last error code used in sedoc
+	// ErrInvalidResult means command result does not conform to declared result schema
+	ErrInvalidResult
+	// ErrInvalidWhereOperator means unknown or not allowed where condition operator
+	ErrInvalidWhereOperator
+	// ErrStreamRequired means command can be executed by streaming transport only
+	ErrStreamRequired
+	// ErrUnknownJob means job not found (or belongs to another session)
+	ErrUnknownJob
+	// ErrJobNotFinished means job is pending or running yet
+	ErrJobNotFinished
+	// ErrJobCanceled means job was canceled
+	ErrJobCanceled
+	// ErrIdempotencyConflict means request idempotency key was used with another payload
+	ErrIdempotencyConflict
+	// ErrRateLimited means request rate limit exceeded, Error RetryAfter is set
+	ErrRateLimited
+	// ErrOverloaded means too many requests are executed at once
+	ErrOverloaded
+	// LastUsedErrorCode is last error code reserved by sedoc (ErrorNamespaceSedoc).
+	// Custom error codes should be declared in ErrorNamespace above it
 	LastUsedErrorCode = 100
 )
 
 // DefaultErrors is map of Mrror
 var DefaultErrors = Errors{
-	{Code: ErrUnknown, Description: "unknown error occurred"},
-	{Code: ErrInvalidRequest, Description: "can't parse request"},
-	{Code: ErrUnknownCommand, Description: "unknown command"},
-	{Code: ErrInvalidArgumentRegExp, Description: "invalid command argument parameter regexp"},
-	{Code: ErrArgumentRegExpMatchFails, Description: "match command argument parameter regexp fails"},
-	{Code: ErrRequiredArgumentMissing, Description: "require command argument parameter missing"},
-	{Code: ErrUnknownArgument, Description: "unknown command argument parameter in request"},
-	{Code: ErrInvalidArgumentValue, Description: "invalid command argument parameter value"},
+	{Code: ErrUnknown, Description: "unknown error occurred", HTTPStatus: http.StatusInternalServerError, GRPCCode: grpcUnknown},
+	{Code: ErrInvalidRequest, Description: "can't parse request", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	{Code: ErrUnknownCommand, Description: "unknown command", HTTPStatus: http.StatusNotFound, GRPCCode: grpcUnimplemented},
+	{Code: ErrInvalidArgumentRegExp, Description: "invalid command argument parameter regexp", HTTPStatus: http.StatusInternalServerError, GRPCCode: grpcInternal},
+	{Code: ErrArgumentRegExpMatchFails, Description: "match command argument parameter regexp fails", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	{Code: ErrRequiredArgumentMissing, Description: "require command argument parameter missing", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	{Code: ErrUnknownArgument, Description: "unknown command argument parameter in request", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	{Code: ErrInvalidArgumentValue, Description: "invalid command argument parameter value", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	{Code: ErrInvalidResult, Description: "command result does not conform to declared schema", HTTPStatus: http.StatusInternalServerError, GRPCCode: grpcInternal},
+	{Code: ErrInvalidWhereOperator, Description: "unknown or not allowed where condition operator", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcInvalidArgument},
+	{Code: ErrStreamRequired, Description: "command requires streaming transport", HTTPStatus: http.StatusBadRequest, GRPCCode: grpcFailedPrecondition},
+	{Code: ErrUnknownJob, Description: "unknown job", HTTPStatus: http.StatusNotFound, GRPCCode: grpcNotFound},
+	{Code: ErrJobNotFinished, Description: "job is not finished", HTTPStatus: http.StatusConflict, GRPCCode: grpcFailedPrecondition},
+	{Code: ErrJobCanceled, Description: "job was canceled", HTTPStatus: http.StatusGone, GRPCCode: grpcCanceled},
+	{Code: ErrIdempotencyConflict, Description: "idempotency key is already used with another payload", HTTPStatus: http.StatusUnprocessableEntity, GRPCCode: grpcAborted},
+	{Code: ErrRateLimited, Description: "rate limit exceeded", HTTPStatus: http.StatusTooManyRequests, GRPCCode: grpcResourceExhausted},
+	{Code: ErrOverloaded, Description: "server is overloaded", HTTPStatus: http.StatusServiceUnavailable, GRPCCode: grpcUnavailable},
 }
 
 // Errors is array of Error
@@ -85,12 +233,22 @@ func (arr *Errors) Contains(code int) bool {
 	return false
 }
 
-// Add is add error to array
+// Add is add error to array. Add panics if error code already exist.
+// Error without HTTPStatus or GRPCCode is reported as 500 Internal Server
+// Error or gRPC Unknown (see Error HTTP and GRPC)
 func (arr *Errors) Add(err Error) {
-	if arr.Contains(err.Code) {
+	if e := arr.Append(err); e != nil {
 		panic("api: duplicate error")
 	}
+}
+
+// Append is add error to array, return error if error code already exist
+func (arr *Errors) Append(err Error) error {
+	if arr.Contains(err.Code) {
+		return fmt.Errorf("api: duplicate error: %d", err.Code)
+	}
 	*arr = append(*arr, err)
+	return nil
 }
 
 // Get is get error from array
@@ -0,0 +1,155 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestError_Is(t *testing.T) {
+	internal := io.EOF
+	err := fmt.Errorf("wrapped: %w", New().NewErrorInternal(ErrInvalidArgumentValue, internal))
+	if !errors.Is(err, &Error{Code: ErrInvalidArgumentValue}) || !errors.Is(err, Error{Code: ErrInvalidArgumentValue}) {
+		t.Error("errors.Is() = false, want true")
+	}
+	if errors.Is(err, &Error{Code: ErrUnknown}) {
+		t.Error("errors.Is() of another code = true, want false")
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("errors.Is() of Internal = false, want true")
+	}
+	var serr *Error
+	if !errors.As(err, &serr) || serr.Code != ErrInvalidArgumentValue {
+		t.Errorf("errors.As() = %v", serr)
+	}
+}
+
+func TestAPI_Execute_wrappedError(t *testing.T) {
+	a := New()
+	a.Use(CheckErrors)
+	a.AddCommand(Command{
+		Name:    "job.load",
+		Errors:  []int{ErrUnknownJob},
+		Handler: func(c Context) error { return fmt.Errorf("loading: %w", c.Error(ErrUnknownJob)) },
+	})
+	resp := a.Execute(&Request{Command: "job.load"})
+	if resp.Error == nil || resp.Error.Code != ErrUnknownJob || !errors.Is(resp.Error, &Error{Code: ErrUnknownJob}) {
+		t.Fatalf("Execute() error = %v, want code %d", resp.Error, ErrUnknownJob)
+	}
+	if resp.Error.Internal == nil || !strings.HasPrefix(resp.Error.Internal.Error(), "loading: ") {
+		t.Errorf("Error.Internal = %v, want wrapping error", resp.Error.Internal)
+	}
+	if resp.Meta != nil && len(resp.Meta.Warnings) > 0 {
+		t.Errorf("CheckErrors() warnings = %v", resp.Meta.Warnings)
+	}
+}
+
+func TestError_status(t *testing.T) {
+	a := New()
+	a.Errors.Add(Error{Code: 1001, Description: "custom unmapped"})
+	a.Errors.Add(Error{Code: 1002, Description: "custom mapped", HTTPStatus: http.StatusConflict, GRPCCode: grpcAborted})
+	tests := []struct {
+		code int
+		http int
+		grpc int
+	}{
+		{ErrUnknownCommand, http.StatusNotFound, grpcUnimplemented},
+		{ErrInvalidArgumentValue, http.StatusBadRequest, grpcInvalidArgument},
+		{ErrRateLimited, http.StatusTooManyRequests, grpcResourceExhausted},
+		{LastUsedErrorCode + 1, http.StatusInternalServerError, grpcUnknown},
+		{1001, http.StatusInternalServerError, grpcUnknown},
+		{1002, http.StatusConflict, grpcAborted},
+	}
+	for _, tt := range tests {
+		err := a.NewError(tt.code)
+		if err.HTTP() != tt.http || err.GRPC() != tt.grpc {
+			t.Errorf("code %d: HTTP() = %d, GRPC() = %d, want %d, %d", tt.code, err.HTTP(), err.GRPC(), tt.http, tt.grpc)
+		}
+	}
+}
+
+func TestAPI_NewError_details(t *testing.T) {
+	detail := ErrorDetail{Field: "args.login", Reason: "taken"}
+	err := New().NewError(ErrInvalidArgumentValue, "login", &detail, 42)
+	if err.Description != "invalid command argument parameter value: login 42" {
+		t.Errorf("Description = %q", err.Description)
+	}
+	if !reflect.DeepEqual(err.Details, []ErrorDetail{detail}) {
+		t.Errorf("Details = %v", err.Details)
+	}
+}
+
+func TestError_encoding(t *testing.T) {
+	err := Error{Code: ErrInvalidArgumentValue, Description: "invalid", HTTPStatus: http.StatusBadRequest, Details: []ErrorDetail{
+		{Field: "args.count", Reason: "invalid type", Expected: "integer", Actual: "foo"},
+	}}
+	tests := []struct {
+		name    string
+		marshal func(interface{}) ([]byte, error)
+		want    string
+	}{
+		{"json", json.Marshal, `{"code":8,"desc":"invalid","details":[{"field":"args.count","reason":"invalid type","expected":"integer","actual":"foo"}]}`},
+		{"xml", xml.Marshal, `<error code="8" desc="invalid"><detail field="args.count" reason="invalid type" expected="integer" actual="foo"></detail></error>`},
+		{"yaml", yaml.Marshal, "code: 8\ndesc: invalid\ndetails:\n- field: args.count\n  reason: invalid type\n  expected: integer\n  actual: foo\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, e := tt.marshal(err)
+			if e != nil {
+				t.Fatal(e)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPI_Execute_errorDetails(t *testing.T) {
+	a := New()
+	a.AddCommand(Command{
+		Name:    "user.list",
+		Handler: func(c Context) error { return nil },
+		Arguments: Arguments{{Name: "count", Type: ArgumentTypeInteger}, {Name: "login", Type: ArgumentTypeString, RegExp: "^[a-z]+$"},
+			{Name: "password", Type: ArgumentTypeString, RegExp: "^[a-z]+$", Sensitive: true}},
+		Where: Arguments{{Name: "created", Type: ArgumentTypeTime, Operators: ComparisonOperators},
+			{Name: "token", Type: ArgumentTypeInteger, Sensitive: true}},
+	})
+	tests := []struct {
+		name    string
+		request *Request
+		want    ErrorDetail
+	}{
+		{"type", &Request{Command: "user.list", Arguments: InterfaceMap{"count": "foo"}},
+			ErrorDetail{Field: "args.count", Reason: "invalid type", Expected: "integer", Actual: "foo"}},
+		{"unknown", &Request{Command: "user.list", Arguments: InterfaceMap{"foo": 1}},
+			ErrorDetail{Field: "args.foo", Reason: "unknown"}},
+		{"regexp", &Request{Command: "user.list", Arguments: InterfaceMap{"login": "Foo"}},
+			ErrorDetail{Field: "args.login", Reason: "regexp mismatch", Expected: "^[a-z]+$", Actual: "Foo"}},
+		{"sensitive regexp", &Request{Command: "user.list", Arguments: InterfaceMap{"password": "Secret123!"}},
+			ErrorDetail{Field: "args.password", Reason: "regexp mismatch", Expected: "^[a-z]+$", Actual: RedactedValue}},
+		{"sensitive where type", &Request{Command: "user.list", Where: []InterfaceMap{{"token": "secret"}}},
+			ErrorDetail{Field: "where[0].token", Reason: "invalid type", Expected: "integer", Actual: RedactedValue}},
+		{"operator", &Request{Command: "user.list", Where: []InterfaceMap{{"created[like]": "2018"}}},
+			ErrorDetail{Field: "where[0].created[like]", Reason: "operator not allowed", Expected: ComparisonOperators.String(), Actual: "like"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := a.Execute(tt.request)
+			if resp.Error == nil || len(resp.Error.Details) != 1 {
+				t.Fatalf("Execute() error = %v", resp.Error)
+			}
+			if got := resp.Error.Details[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Details[0] = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,7 @@
 package sedoc
 
 import (
-	"encoding/json"
 	"encoding/xml"
-
-	yaml "gopkg.in/yaml.v2"
 )
 
 // Example using for show how to use command
@@ -28,72 +25,76 @@ func (arr Examples) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return MarshallerXML(a)(e, start)
 }
 
-// ExampleRequest using for show how to use command
+// ExampleRequest using for show how to use command.
+// Encodings is Object rendered by API Codecs (filled by help command)
 type ExampleRequest struct {
-	XMLName xml.Name `json:"-" xml:"request" yaml:"-"`
-	Object  Request  `json:"request" yaml:"request"`
-	JSON    string   `json:"json,omitempty" xml:"json,omitempty" yaml:"json,omitempty"`
-	XML     string   `json:"xml,omitempty" xml:"xml,omitempty" yaml:"xml,omitempty"`
-	YAML    string   `json:"yaml,omitempty" xml:"yaml,omitempty" yaml:"yaml,omitempty"`
+	XMLName   xml.Name  `json:"-" xml:"request" yaml:"-"`
+	Object    Request   `json:"request" yaml:"request"`
+	Encodings Encodings `json:"encodings,omitempty" xml:"encodings,omitempty" yaml:"encodings,omitempty"`
 }
 
-// XMLString method
-func (er ExampleRequest) XMLString() (result string) {
-	if b, err := xml.MarshalIndent(er.Object, "", "    "); err == nil {
-		result = xml.Header + string(b)
-	}
+// Render return Object rendered by Codec
+func (er ExampleRequest) Render(c Codec) (result string) {
+	result, _ = RenderExample(c, er.Object)
 	return
 }
 
+// XMLString method
+//
+// Deprecated: use Render(XMLCodec)
+func (er ExampleRequest) XMLString() string {
+	return er.Render(XMLCodec)
+}
+
 // JSONString method
-func (er ExampleRequest) JSONString() (result string) {
-	if b, err := json.MarshalIndent(er.Object, "", "    "); err == nil {
-		result = string(b)
-	}
-	return
+//
+// Deprecated: use Render(JSONCodec)
+func (er ExampleRequest) JSONString() string {
+	return er.Render(JSONCodec)
 }
 
 // YAMLString method
-func (er ExampleRequest) YAMLString() (result string) {
-	if b, err := yaml.Marshal(er.Object); err == nil {
-		result = string(b)
-	}
-	return
+//
+// Deprecated: use Render(YAMLCodec)
+func (er ExampleRequest) YAMLString() string {
+	return er.Render(YAMLCodec)
 }
 
-// ExampleResponse using for show how to use command
+// ExampleResponse using for show how to use command.
+// Encodings is Object rendered by API Codecs (filled by help command)
 type ExampleResponse struct {
-	XMLName     xml.Name `json:"-" xml:"response" yaml:"-"`
-	Name        string   `json:"name,omitempty" xml:"name,attr,omitempty" yaml:"name,omitempty"`
-	Description string   `json:"description,omitempty" xml:"description,attr,omitempty" yaml:"description,omitempty"`
-	Object      Response `json:"response" yaml:"response"`
-	JSON        string   `json:"json,omitempty" xml:"json,omitempty" yaml:"json,omitempty"`
-	XML         string   `json:"xml,omitempty" xml:"xml,omitempty" yaml:"xml,omitempty"`
-	YAML        string   `json:"yaml,omitempty" xml:"yaml,omitempty" yaml:"yaml,omitempty"`
+	XMLName     xml.Name  `json:"-" xml:"response" yaml:"-"`
+	Name        string    `json:"name,omitempty" xml:"name,attr,omitempty" yaml:"name,omitempty"`
+	Description string    `json:"description,omitempty" xml:"description,attr,omitempty" yaml:"description,omitempty"`
+	Object      Response  `json:"response" yaml:"response"`
+	Encodings   Encodings `json:"encodings,omitempty" xml:"encodings,omitempty" yaml:"encodings,omitempty"`
 }
 
-// XMLString method
-func (er ExampleResponse) XMLString() (result string) {
-	if b, err := xml.MarshalIndent(er.Object, "", "    "); err == nil {
-		result = xml.Header + string(b)
-	}
+// Render return Object rendered by Codec
+func (er ExampleResponse) Render(c Codec) (result string) {
+	result, _ = RenderExample(c, er.Object)
 	return
 }
 
+// XMLString method
+//
+// Deprecated: use Render(XMLCodec)
+func (er ExampleResponse) XMLString() string {
+	return er.Render(XMLCodec)
+}
+
 // JSONString method
-func (er ExampleResponse) JSONString() (result string) {
-	if b, err := json.MarshalIndent(er.Object, "", "    "); err == nil {
-		result = string(b)
-	}
-	return
+//
+// Deprecated: use Render(JSONCodec)
+func (er ExampleResponse) JSONString() string {
+	return er.Render(JSONCodec)
 }
 
 // YAMLString method
-func (er ExampleResponse) YAMLString() (result string) {
-	if b, err := yaml.Marshal(er.Object); err == nil {
-		result = string(b)
-	}
-	return
+//
+// Deprecated: use Render(YAMLCodec)
+func (er ExampleResponse) YAMLString() string {
+	return er.Render(YAMLCodec)
 }
 
 // ExampleResponses is array of Command
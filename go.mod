@@ -1,6 +1,6 @@
 module github.com/nsemikov/go-sedoc
 
-go 1.12
+go 1.21
 
 require (
 	github.com/google/uuid v1.1.1
@@ -0,0 +1,39 @@
+package sedoc
+
+import "reflect"
+
+// Handle wraps typed fn into HandlerFunc. In (struct or pointer to struct)
+// is filled by Context.Bind, returned Out is set into Response.Result
+func Handle[In, Out any](fn func(Context, In) (Out, error)) HandlerFunc {
+	return func(c Context) error {
+		var in In
+		dst := interface{}(&in)
+		if t := reflect.TypeOf(in); t != nil && t.Kind() == reflect.Ptr {
+			ptr := reflect.New(t.Elem())
+			in, dst = ptr.Interface().(In), ptr.Interface()
+		}
+		if err := c.Bind(dst); err != nil {
+			return err
+		}
+		out, err := fn(c, in)
+		if err != nil {
+			return err
+		}
+		c.Response().Result = out
+		return nil
+	}
+}
+
+// TypedCommand return copy of cmd with Handler built by Handle,
+// Arguments, Where and Set derived from In and Result derived from Out.
+// TypedCommand panics if In is not tagged struct
+func TypedCommand[In, Out any](cmd Command, fn func(Context, In) (Out, error)) Command {
+	var in In
+	var out Out
+	if err := cmd.ArgumentsOf(in); err != nil {
+		panic(err)
+	}
+	cmd.Result = ResultOf(out)
+	cmd.Handler = Handle(fn)
+	return cmd
+}
@@ -0,0 +1,75 @@
+package sedoc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type handleIn struct {
+	ID   int    `sedoc:"id,where,required"`
+	Name string `sedoc:"name,set"`
+}
+
+type handleOut struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name,omitempty" desc:"User name"`
+	Tags    []string `json:"tags"`
+	Parent  *handleOut
+	private int
+	Skip    int `json:"-"`
+}
+
+func TestTypedCommand(t *testing.T) {
+	a := New()
+	a.AddCommand(TypedCommand(Command{Name: "user.set"}, func(c Context, in handleIn) (*handleOut, error) {
+		if in.ID == 0 {
+			return nil, errors.New("zero id")
+		}
+		return &handleOut{ID: in.ID, Name: in.Name}, nil
+	}))
+	cmd := a.GetCommand("user.set")
+	if len(cmd.Where) != 1 || len(cmd.Set) != 1 || len(cmd.Result) != 4 {
+		t.Fatalf("TypedCommand() = %+v", cmd)
+	}
+	req := &Request{Command: "user.set", Where: []InterfaceMap{{"id": "3"}}, Set: InterfaceMap{"name": "foo"}}
+	resp := a.Execute(req)
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	if want := (&handleOut{ID: 3, Name: "foo"}); !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("Execute() result = %v, want %v", resp.Result, want)
+	}
+	resp = a.Execute(&Request{Command: "user.set", Where: []InterfaceMap{{"id": 0}}})
+	if resp.Error == nil || resp.Error.Code != ErrUnknown || resp.Result != nil {
+		t.Errorf("Execute() = %v, want error %d", resp, ErrUnknown)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("TypedCommand() with non-struct input want panic, but not")
+		}
+	}()
+	TypedCommand(Command{}, func(c Context, in int) (int, error) { return in, nil })
+}
+
+func TestTypedCommand_pointer(t *testing.T) {
+	a := New()
+	a.AddCommand(TypedCommand(Command{Name: "user.set"}, func(c Context, in *handleIn) (int, error) {
+		return in.ID, nil
+	}))
+	if cmd := a.GetCommand("user.set"); len(cmd.Where) != 1 || len(cmd.Set) != 1 {
+		t.Fatalf("TypedCommand() = %+v", cmd)
+	}
+	resp := a.Execute(&Request{Command: "user.set", Where: []InterfaceMap{{"id": "3"}}})
+	if resp.Error != nil || resp.Result != 3 {
+		t.Errorf("Execute() = %v, %v, want 3", resp.Result, resp.Error)
+	}
+}
+
+func TestHandle_pointer(t *testing.T) {
+	handler := Handle(func(c Context, in *handleIn) (string, error) { return in.Name, nil })
+	c := &context{api: New(), req: &Request{Set: InterfaceMap{"name": "foo"}}}
+	if err := handler(c); err != nil || c.Response().Result != "foo" {
+		t.Errorf("Handle() = %v, %v, want foo", c.Response().Result, err)
+	}
+}
@@ -1,6 +1,7 @@
 package sedoc
 
 import (
+	"net/http"
 	"net/url"
 	"strings"
 )
@@ -15,6 +16,27 @@ func (api API) RequestFromURL(r *Request, u *url.URL) *Request {
 	return request
 }
 
+// HTTP headers used by RequestFromHTTP
+const (
+	// HeaderAcceptLanguage is used for Request Locale
+	HeaderAcceptLanguage = "Accept-Language"
+	// HeaderTraceparent is W3C trace context header used for Request Trace
+	HeaderTraceparent = "traceparent"
+)
+
+// RequestFromHTTP fill request from HTTP request URL query and headers.
+// Request fields take precedence over headers
+func (api API) RequestFromHTTP(r *Request, hr *http.Request) *Request {
+	request := api.RequestFromURL(r, hr.URL)
+	if len(request.Locale) == 0 {
+		request.Locale = hr.Header.Get(HeaderAcceptLanguage)
+	}
+	if len(request.Trace) == 0 {
+		request.Trace = hr.Header.Get(HeaderTraceparent)
+	}
+	return request
+}
+
 func (api API) addToRequest(values url.Values, request *Request) {
 	cmd := api.GetCommand(request.Command)
 	if len(values) > 0 {
@@ -37,7 +59,7 @@ func (api API) addToRequest(values url.Values, request *Request) {
 			} else if strings.Index(key, api.PrefixWhere) == 0 {
 				name := key[len(api.PrefixWhere):]
 				val := interface{}(vals[0])
-				if multiple(cmd.Where, name) {
+				if multipleWhere(cmd.Where, name) {
 					arr := make([]interface{}, len(vals))
 					for idx := range vals {
 						arr[idx] = vals[idx]
@@ -69,6 +91,17 @@ func multiple(args Arguments, name string) bool {
 	return true
 }
 
+func multipleWhere(args Arguments, key string) bool {
+	name, op, _ := ParseWhereKey(key)
+	switch op {
+	case OperatorIn, OperatorBetween:
+		return true
+	case OperatorEq:
+		return multiple(args, name)
+	}
+	return false
+}
+
 func addToRequestSet(set *InterfaceMap, name string, value interface{}) {
 	if *set == nil {
 		*set = make(InterfaceMap)
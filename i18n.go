@@ -0,0 +1,291 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultLocale is locale of builtin descriptions
+const DefaultLocale = "en"
+
+// Catalog is localized messages of API for single locale.
+// Messages missing in Catalog fallback to builtin descriptions
+type Catalog struct {
+	Locale   string                     `json:"locale" yaml:"locale"`
+	Errors   map[int]string             `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Commands map[string]CommandMessages `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// CommandMessages is localized Command description and its Arguments, Where,
+// Set, Result and Events descriptions
+type CommandMessages struct {
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Arguments   map[string]string `json:"args,omitempty" yaml:"args,omitempty"`
+	Where       map[string]string `json:"where,omitempty" yaml:"where,omitempty"`
+	Set         map[string]string `json:"set,omitempty" yaml:"set,omitempty"`
+	Result      map[string]string `json:"result,omitempty" yaml:"result,omitempty"`
+	Events      map[string]string `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// ParseCatalog parse Catalog in format "json" or "yaml"
+func ParseCatalog(b []byte, format string) (c Catalog, err error) {
+	switch strings.ToLower(format) {
+	case "json":
+		err = json.Unmarshal(b, &c)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(b, &c)
+	default:
+		err = fmt.Errorf("sedoc: unknown catalog format: %s", format)
+	}
+	if err == nil && len(c.Locale) == 0 {
+		err = fmt.Errorf("sedoc: catalog locale missing")
+	}
+	return
+}
+
+// LoadCatalog read Catalog from JSON or YAML file (format is detected by file extension)
+func LoadCatalog(path string) (Catalog, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+	c, err := ParseCatalog(b, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return Catalog{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Catalogs is registry of Catalog by locale. It is safe for concurrent use
+type Catalogs struct {
+	mu       sync.RWMutex
+	catalogs map[string]Catalog
+}
+
+// NewCatalogs is Catalogs constructor
+func NewCatalogs(catalogs ...Catalog) *Catalogs {
+	r := &Catalogs{catalogs: map[string]Catalog{}}
+	for _, c := range catalogs {
+		r.Add(c)
+	}
+	return r
+}
+
+// Add Catalog to registry. Messages of Catalog with already added locale are merged
+func (r *Catalogs) Add(c Catalog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	locale := normalizeLocale(c.Locale)
+	old := r.catalogs[locale]
+	merged := Catalog{Locale: locale, Errors: map[int]string{}, Commands: map[string]CommandMessages{}}
+	for _, src := range []Catalog{old, c} {
+		for code, msg := range src.Errors {
+			merged.Errors[code] = msg
+		}
+		for name, msgs := range src.Commands {
+			cm := merged.Commands[name]
+			if len(msgs.Description) > 0 {
+				cm.Description = msgs.Description
+			}
+			cm.Arguments = mergeMessages(cm.Arguments, msgs.Arguments)
+			cm.Where = mergeMessages(cm.Where, msgs.Where)
+			cm.Set = mergeMessages(cm.Set, msgs.Set)
+			cm.Result = mergeMessages(cm.Result, msgs.Result)
+			cm.Events = mergeMessages(cm.Events, msgs.Events)
+			merged.Commands[name] = cm
+		}
+	}
+	r.catalogs[locale] = merged
+}
+
+// Get return Catalog of locale (exact match)
+func (r *Catalogs) Get(locale string) (Catalog, bool) {
+	if r == nil {
+		return Catalog{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.catalogs[normalizeLocale(locale)]
+	return c, ok
+}
+
+// Match return Catalog for locale, which is single tag like "de-AT" or
+// Accept-Language header value like "de-AT,de;q=0.9,en;q=0.5". Tags are
+// matched exactly, then by language. False is returned if nothing matches
+// or DefaultLocale without registered Catalog is preferred (builtin
+// descriptions are used then)
+func (r *Catalogs) Match(locale string) (Catalog, bool) {
+	for _, tag := range parseLocales(locale) {
+		candidates := []string{tag}
+		if base, _, ok := strings.Cut(tag, "-"); ok {
+			candidates = append(candidates, base)
+		}
+		for _, candidate := range candidates {
+			if c, ok := r.Get(candidate); ok {
+				return c, true
+			}
+			if candidate == DefaultLocale {
+				return Catalog{}, false
+			}
+		}
+	}
+	return Catalog{}, false
+}
+
+// Error return localized description of error code
+func (c Catalog) Error(code int) (string, bool) {
+	msg, ok := c.Errors[code]
+	return msg, ok && len(msg) > 0
+}
+
+// localizeCommand return copy of cmd with localized descriptions
+func (c Catalog) localizeCommand(cmd Command) Command {
+	msgs, ok := c.Commands[cmd.Name]
+	if !ok {
+		return cmd
+	}
+	if len(msgs.Description) > 0 {
+		cmd.Description = msgs.Description
+	}
+	cmd.Arguments = localizeArguments(cmd.Arguments, msgs.Arguments)
+	cmd.Where = localizeArguments(cmd.Where, msgs.Where)
+	cmd.Set = localizeArguments(cmd.Set, msgs.Set)
+	cmd.Result = localizeArguments(cmd.Result, msgs.Result)
+	cmd.Events = localizeArguments(cmd.Events, msgs.Events)
+	return cmd
+}
+
+func localizeArguments(args Arguments, msgs map[string]string) Arguments {
+	if len(msgs) == 0 {
+		return args
+	}
+	res := make(Arguments, len(args))
+	for idx, arg := range args {
+		if msg, ok := msgs[arg.Name]; ok && len(msg) > 0 {
+			arg.Description = msg
+		}
+		res[idx] = arg
+	}
+	return res
+}
+
+func mergeMessages(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for key, msg := range src {
+		dst[key] = msg
+	}
+	return dst
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// parseLocales parse Accept-Language like value into tags ordered by quality
+func parseLocales(s string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(s, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = normalizeLocale(tag)
+		if len(tag) == 0 || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	res := make([]string, len(tags))
+	for idx := range tags {
+		res[idx] = tags[idx].tag
+	}
+	return res
+}
+
+// AddCatalog add Catalog to API Catalogs
+func (api *API) AddCatalog(c Catalog) {
+	if api.Catalogs == nil {
+		api.Catalogs = NewCatalogs()
+	}
+	api.Catalogs.Add(c)
+}
+
+// LoadCatalogs load Catalog files (JSON or YAML) into API Catalogs
+func (api *API) LoadCatalogs(paths ...string) error {
+	for _, path := range paths {
+		c, err := LoadCatalog(path)
+		if err != nil {
+			return err
+		}
+		api.AddCatalog(c)
+	}
+	return nil
+}
+
+// Localized return copy of API with Commands and Errors descriptions
+// localized for locale (api itself if there is no matching Catalog)
+func (api *API) Localized(locale string) *API {
+	c, ok := api.Catalogs.Match(locale)
+	if !ok {
+		return api
+	}
+	loc := *api
+	loc.Errors = make(Errors, len(api.Errors))
+	for idx, err := range api.Errors {
+		if msg, ok := c.Error(err.Code); ok {
+			err.Description = msg
+		}
+		loc.Errors[idx] = err
+	}
+	loc.Commands = make(Commands, len(api.Commands))
+	for idx, cmd := range api.Commands {
+		loc.Commands[idx] = c.localizeCommand(cmd)
+	}
+	return &loc
+}
+
+// localizeError replace builtin description of Response Error by localized for request locale
+func (api *API) localizeError(c *context) {
+	serr := c.Response().Error
+	if serr == nil || len(c.Request().Locale) == 0 {
+		return
+	}
+	catalog, ok := api.Catalogs.Match(c.Request().Locale)
+	if !ok {
+		return
+	}
+	msg, ok := catalog.Error(serr.Code)
+	if !ok {
+		return
+	}
+	localized := *serr
+	if builtin := api.Errors.Get(serr.Code).Description; len(builtin) == 0 {
+		localized.Description = msg
+	} else if strings.Contains(serr.Description, builtin) {
+		localized.Description = strings.Replace(serr.Description, builtin, msg, 1)
+	}
+	c.Response().Error = &localized
+}
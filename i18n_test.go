@@ -0,0 +1,137 @@
+package sedoc
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newLocalizedAPI(t *testing.T) *API {
+	a := New()
+	a.AddCommand(Command{
+		Name:        "user.get",
+		Description: "Get user",
+		Arguments:   Arguments{{Name: "id", Type: ArgumentTypeInteger, Description: "User identifier"}},
+		Result:      Arguments{{Name: "name", Type: ArgumentTypeString, Description: "User name"}},
+		Events:      Arguments{{Name: "status", Type: ArgumentTypeString, Description: "User status"}},
+		Handler:     func(c Context) error { return nil },
+	})
+	dir := t.TempDir()
+	files := map[string]string{
+		"ru.json": `{"locale":"ru","errors":{"8":"неверное значение параметра"},"commands":{"user.get":{"description":"Получить пользователя","args":{"id":"Идентификатор"},"result":{"name":"Имя"},"events":{"status":"Статус"}}}}`,
+		"de.yaml": "locale: de\nerrors:\n  3: unbekannter Befehl\n",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	if err := a.LoadCatalogs(paths...); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestCatalogs_Match(t *testing.T) {
+	r := NewCatalogs(Catalog{Locale: "de"}, Catalog{Locale: "pt_BR"})
+	tests := []struct {
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"de", "de", true},
+		{"de-AT", "de", true},
+		{"pt-br", "pt-br", true},
+		{"fr, de;q=0.5", "de", true},
+		{"en;q=0.9, de;q=0.8", "", false},
+		{"de;q=0, fr", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		c, ok := r.Match(tt.locale)
+		if ok != tt.ok || c.Locale != tt.want {
+			t.Errorf("Match(%q) = %q, %v, want %q, %v", tt.locale, c.Locale, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCatalogs_Match_default(t *testing.T) {
+	r := NewCatalogs(Catalog{Locale: "en", Errors: map[int]string{ErrUnknown: "something went wrong"}}, Catalog{Locale: "de"})
+	for _, locale := range []string{"en", "en-US", "en;q=0.9, de;q=0.8"} {
+		if c, ok := r.Match(locale); !ok || c.Locale != "en" {
+			t.Errorf("Match(%q) = %q, %v, want en", locale, c.Locale, ok)
+		}
+	}
+}
+
+func TestCatalogs_Add_merge(t *testing.T) {
+	r := NewCatalogs(
+		Catalog{Locale: "de", Errors: map[int]string{ErrUnknown: "unbekannter Fehler"}},
+		Catalog{Locale: "DE", Commands: map[string]CommandMessages{"help": {Description: "Hilfe"}}},
+	)
+	c, ok := r.Get("de")
+	if !ok || c.Errors[ErrUnknown] != "unbekannter Fehler" || c.Commands["help"].Description != "Hilfe" {
+		t.Errorf("Get() = %+v, %v", c, ok)
+	}
+}
+
+func TestParseCatalog_invalid(t *testing.T) {
+	if _, err := ParseCatalog([]byte(`{}`), "json"); err == nil {
+		t.Error("ParseCatalog() without locale error = nil")
+	}
+	if _, err := ParseCatalog([]byte(`locale: de`), "toml"); err == nil {
+		t.Error("ParseCatalog() of unknown format error = nil")
+	}
+}
+
+func TestAPI_Execute_localizedError(t *testing.T) {
+	a := newLocalizedAPI(t)
+	tests := []struct {
+		name    string
+		request *Request
+		want    string
+	}{
+		{"builtin", &Request{Command: "unknown"}, "unknown command"},
+		{"de", &Request{Command: "unknown", Locale: "de-DE"}, "unbekannter Befehl"},
+		{"fallback", &Request{Command: "unknown", Locale: "ru"}, "unknown command"},
+		{"suffix", &Request{Command: "user.get", Locale: "ru", Arguments: InterfaceMap{"id": "foo"}},
+			"args: неверное значение параметра (id): 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := a.Execute(tt.request)
+			if resp.Error == nil || resp.Error.Description != tt.want {
+				t.Errorf("Execute() error = %v, want %q", resp.Error, tt.want)
+			}
+		})
+	}
+	if desc := a.Errors.Get(ErrUnknownCommand).Description; desc != "unknown command" {
+		t.Errorf("API Errors description changed: %q", desc)
+	}
+}
+
+func TestAPI_Execute_localizedHelp(t *testing.T) {
+	a := newLocalizedAPI(t)
+	hr, _ := http.NewRequest(http.MethodGet, "/", nil)
+	hr.Header.Set(HeaderAcceptLanguage, "ru-RU,ru;q=0.9")
+	resp := a.Execute(a.RequestFromHTTP(&Request{Command: "help"}, hr))
+	loc, ok := resp.Result.(*API)
+	if !ok {
+		t.Fatalf("Result = %T", resp.Result)
+	}
+	cmd, _ := loc.Commands.Get("user.get")
+	if cmd.Description != "Получить пользователя" || cmd.Arguments[0].Description != "Идентификатор" ||
+		cmd.Result[0].Description != "Имя" || cmd.Events[0].Description != "Статус" {
+		t.Errorf("localized command = %+v", cmd)
+	}
+	if e := loc.Errors.Get(ErrInvalidArgumentValue); e.Description != "неверное значение параметра" {
+		t.Errorf("localized error = %+v", e)
+	}
+	if cmd, _ = a.Commands.Get("user.get"); cmd.Description != "Get user" || cmd.Arguments[0].Description != "User identifier" {
+		t.Errorf("API command changed: %+v", cmd)
+	}
+}
@@ -0,0 +1,241 @@
+package sedoc
+
+import (
+	"container/heap"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// IdempotencyEntry is Response stored by Idempotency middleware
+type IdempotencyEntry struct {
+	// Fingerprint is hash of request command, arguments, where and set
+	Fingerprint string
+	Response    Response
+	Expires     time.Time
+}
+
+// IdempotencyStore is cache of IdempotencyEntry. It must be safe for concurrent use
+type IdempotencyStore interface {
+	// Get return not expired entry by key
+	Get(key string) (entry IdempotencyEntry, ok bool, err error)
+	// Set store entry by key until entry Expires
+	Set(key string, entry IdempotencyEntry) error
+}
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]IdempotencyEntry
+	expires expiryHeap
+}
+
+// NewMemoryIdempotencyStore return in-memory IdempotencyStore.
+// Expired entries are removed on Get and Set
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{entries: map[string]IdempotencyEntry{}}
+}
+
+func (s *memoryIdempotencyStore) Get(key string) (IdempotencyEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(time.Now())
+	entry, ok := s.entries[key]
+	return entry, ok, nil
+}
+
+func (s *memoryIdempotencyStore) Set(key string, entry IdempotencyEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(time.Now())
+	s.entries[key] = entry
+	heap.Push(&s.expires, expiry{key: key, at: entry.Expires})
+	return nil
+}
+
+// expire remove entries expired at now, s.mu must be locked
+func (s *memoryIdempotencyStore) expire(now time.Time) {
+	for len(s.expires) > 0 && !s.expires[0].at.After(now) {
+		item := heap.Pop(&s.expires).(expiry)
+		// entry may be replaced after item was pushed
+		if entry, ok := s.entries[item.key]; ok && !entry.Expires.After(now) {
+			delete(s.entries, item.key)
+		}
+	}
+}
+
+type expiry struct {
+	key string
+	at  time.Time
+}
+
+// expiryHeap is min-heap of entries expiration times
+type expiryHeap []expiry
+
+func (h expiryHeap) Len() int            { return len(h) }
+func (h expiryHeap) Less(i, j int) bool  { return h[i].at.Before(h[j].at) }
+func (h expiryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x interface{}) { *h = append(*h, x.(expiry)) }
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// IdempotencyConfig is Idempotency middleware config
+type IdempotencyConfig struct {
+	// Store of first responses, in-memory store by default
+	Store IdempotencyStore
+	// TTL of stored response, 24 hours by default
+	TTL time.Duration
+	// Argument is name of request argument with idempotency key.
+	// Request ID is used as key if Argument is empty
+	Argument string
+	// Skipper return true for requests which are not checked.
+	// By default requests without Set are skipped
+	Skipper func(Context) bool
+}
+
+// DefaultIdempotencyTTL is default IdempotencyConfig TTL
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// Idempotency return middleware which replays first Response for requests
+// with the same idempotency key (Request ID), command and session within ttl.
+// Request with the same key but another payload fails with ErrIdempotencyConflict
+func Idempotency(store IdempotencyStore, ttl time.Duration) MiddlewareFunc {
+	return IdempotencyWithConfig(IdempotencyConfig{Store: store, TTL: ttl})
+}
+
+// IdempotencyWithConfig return Idempotency middleware with config.
+// Only successful responses are stored, so failed requests may be retried
+func IdempotencyWithConfig(config IdempotencyConfig) MiddlewareFunc {
+	if config.Store == nil {
+		config.Store = NewMemoryIdempotencyStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultIdempotencyTTL
+	}
+	if config.Skipper == nil {
+		config.Skipper = func(c Context) bool { return len(c.Request().Set) == 0 }
+	}
+	locks := &keyLocks{locks: map[string]*keyLock{}}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			req := c.Request()
+			id := req.ID
+			if len(config.Argument) > 0 {
+				id, _ = req.Arguments[config.Argument].(string)
+			}
+			if len(id) == 0 {
+				return next(c)
+			}
+			key := req.Session + "\x00" + req.Command + "\x00" + id
+			fingerprint, err := idempotencyFingerprint(req, config.Argument)
+			if err != nil {
+				return c.ErrorInternal(ErrUnknown, err)
+			}
+			// duplicates in flight wait for the first request
+			unlock := locks.lock(key)
+			defer unlock()
+			entry, ok, err := config.Store.Get(key)
+			if err != nil {
+				return c.ErrorInternal(ErrUnknown, err)
+			}
+			if ok {
+				if entry.Fingerprint != fingerprint {
+					return c.Error(ErrIdempotencyConflict, id)
+				}
+				*c.Response() = cloneResponse(entry.Response)
+				return nil
+			}
+			if err = next(c); err != nil {
+				return err
+			}
+			entry = IdempotencyEntry{Fingerprint: fingerprint, Response: cloneResponse(*c.Response()), Expires: time.Now().Add(config.TTL)}
+			if err = config.Store.Set(key, entry); err != nil {
+				c.Warn("idempotency: response is not stored: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// cloneResponse copy Response with its Arguments, Page, Meta and Error and
+// maps and slices of Result, so stored Response is not shared with requests
+// it is replayed to. Other Result values (like pointers to structs) are
+// shared, handler must not modify them after return
+func cloneResponse(r Response) Response {
+	r.Arguments = cloneMap(r.Arguments)
+	r.Result = cloneValue(r.Result)
+	if r.Page != nil {
+		page := *r.Page
+		if page.Total != nil {
+			total := *page.Total
+			page.Total = &total
+		}
+		r.Page = &page
+	}
+	if r.Meta != nil {
+		meta := *r.Meta
+		meta.Warnings = append([]string(nil), meta.Warnings...)
+		r.Meta = &meta
+	}
+	if r.Error != nil {
+		serr := *r.Error
+		serr.Details = append([]ErrorDetail(nil), serr.Details...)
+		r.Error = &serr
+	}
+	return r
+}
+
+func idempotencyFingerprint(req *Request, skip string) (string, error) {
+	args := InterfaceMap{}
+	for k, v := range req.Arguments {
+		if k != skip {
+			args[k] = v
+		}
+	}
+	// json.Marshal sorts map keys, so equal payloads have equal fingerprints
+	b, err := json.Marshal([]interface{}{req.Command, args, req.Where, req.Set})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// keyLocks is set of mutexes by key, removed when not used
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	kl := l.locks[key]
+	if kl == nil {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+	kl.mu.Lock()
+	return func() {
+		kl.mu.Unlock()
+		l.mu.Lock()
+		if kl.refs--; kl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,156 @@
+package sedoc
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newIdempotencyAPI(config IdempotencyConfig, created *int32) *API {
+	a := New()
+	a.Use(IdempotencyWithConfig(config))
+	a.AddCommand(Command{
+		Name:      "user.create",
+		Arguments: Arguments{{Name: "key", Type: ArgumentTypeString}},
+		Set:       Arguments{{Name: "name", Type: ArgumentTypeString}},
+		Handler: func(c Context) error {
+			if c.Request().Set["name"] == "fail" {
+				return c.Error(ErrInvalidArgumentValue, "fail")
+			}
+			c.Response().Result = InterfaceMap{"id": atomic.AddInt32(created, 1)}
+			return nil
+		},
+	})
+	return a
+}
+
+func TestIdempotency(t *testing.T) {
+	var created int32
+	a := newIdempotencyAPI(IdempotencyConfig{}, &created)
+	req := func(id, session, name string) *Request {
+		return &Request{ID: id, Session: session, Command: "user.create", Set: InterfaceMap{"name": name}}
+	}
+	first := a.Execute(req("1", "", "foo"))
+	tests := []struct {
+		name     string
+		req      *Request
+		wantID   int32
+		wantCode int
+	}{
+		{"replay", req("1", "", "foo"), 1, 0},
+		{"conflict", req("1", "", "bar"), 0, ErrIdempotencyConflict},
+		{"another key", req("2", "", "foo"), 2, 0},
+		{"another session", req("1", "01234567-89ab-cdef-0123-456789abcdef", "foo"), 3, 0},
+		{"without key", req("", "", "foo"), 4, 0},
+		{"without key again", req("", "", "foo"), 5, 0},
+		{"error is not stored", req("3", "", "fail"), 0, ErrInvalidArgumentValue},
+		{"error retry", req("3", "", "fail"), 0, ErrInvalidArgumentValue},
+	}
+	if first.Error != nil || first.Result.(InterfaceMap)["id"] != int32(1) {
+		t.Fatalf("first Execute() = %v", first)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := a.Execute(tt.req)
+			code := 0
+			if resp.Error != nil {
+				code = resp.Error.Code
+			}
+			if code != tt.wantCode {
+				t.Fatalf("Execute() error = %v, want code %d", resp.Error, tt.wantCode)
+			}
+			if code == 0 && resp.Result.(InterfaceMap)["id"] != tt.wantID {
+				t.Errorf("Execute() result = %v, want id %d", resp.Result, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestIdempotency_argument(t *testing.T) {
+	var created int32
+	a := newIdempotencyAPI(IdempotencyConfig{Argument: "key", TTL: 20 * time.Millisecond}, &created)
+	req := func(key string) *Request {
+		return &Request{ID: "ignored", Command: "user.create", Arguments: InterfaceMap{"key": key}, Set: InterfaceMap{"name": "foo"}}
+	}
+	var wg sync.WaitGroup
+	for idx := 0; idx < 10; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp := a.Execute(req("k")); resp.Error != nil {
+				t.Errorf("Execute() error = %v", resp.Error)
+			}
+		}()
+	}
+	wg.Wait()
+	if created != 1 {
+		t.Errorf("created %d times, want 1", created)
+	}
+	if resp := a.Execute(req("l")); resp.Result.(InterfaceMap)["id"] != int32(2) {
+		t.Errorf("Execute() with another key = %v", resp.Result)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if resp := a.Execute(req("k")); resp.Result.(InterfaceMap)["id"] != int32(3) {
+		t.Errorf("Execute() after TTL = %v", resp.Result)
+	}
+}
+
+func TestIdempotency_replayIsolated(t *testing.T) {
+	a := New()
+	a.Use(IdempotencyWithConfig(IdempotencyConfig{}))
+	a.AddCommand(Command{
+		Name: "user.create",
+		Set:  Arguments{{Name: "name", Type: ArgumentTypeString}},
+		Handler: func(c Context) error {
+			c.Warn("created")
+			c.Response().Result = InterfaceMap{"tags": []interface{}{"a"}}
+			return nil
+		},
+	})
+	req := &Request{ID: "1", Command: "user.create", Set: InterfaceMap{"name": "foo"}}
+	for idx := 0; idx < 3; idx++ {
+		resp := a.Execute(req)
+		if resp.Meta == nil || len(resp.Meta.Warnings) != 1 {
+			t.Fatalf("Execute() #%d meta = %+v, want single warning", idx, resp.Meta)
+		}
+		if tags := resp.Result.(InterfaceMap)["tags"].([]interface{}); len(tags) != 1 || tags[0] != "a" {
+			t.Fatalf("Execute() #%d result = %v", idx, resp.Result)
+		}
+		resp.Meta.Warnings = append(resp.Meta.Warnings, "modified")
+		resp.Result.(InterfaceMap)["tags"].([]interface{})[0] = "modified"
+	}
+}
+
+func TestIdempotency_command(t *testing.T) {
+	var created int32
+	a := newIdempotencyAPI(IdempotencyConfig{}, &created)
+	a.AddCommand(Command{
+		Name:    "user.update",
+		Set:     Arguments{{Name: "name", Type: ArgumentTypeString}},
+		Handler: func(c Context) error { return nil },
+	})
+	if resp := a.Execute(&Request{ID: "1", Command: "user.create", Set: InterfaceMap{"name": "foo"}}); resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	if resp := a.Execute(&Request{ID: "1", Command: "user.update", Set: InterfaceMap{"name": "bar"}}); resp.Error != nil {
+		t.Errorf("Execute() of another command error = %v", resp.Error)
+	}
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	s := NewMemoryIdempotencyStore()
+	now := time.Now()
+	_ = s.Set("expired", IdempotencyEntry{Expires: now.Add(-time.Second)})
+	_ = s.Set("replaced", IdempotencyEntry{Expires: now.Add(-time.Second)})
+	_ = s.Set("replaced", IdempotencyEntry{Fingerprint: "new", Expires: now.Add(time.Hour)})
+	_ = s.Set("valid", IdempotencyEntry{Expires: now.Add(time.Hour)})
+	for key, want := range map[string]bool{"expired": false, "replaced": true, "valid": true} {
+		if _, ok, err := s.Get(key); ok != want || err != nil {
+			t.Errorf("Get(%q) = %v, %v, want %v", key, ok, err, want)
+		}
+	}
+	if n := len(s.(*memoryIdempotencyStore).entries); n != 2 {
+		t.Errorf("store has %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,642 @@
+// Package codec is reflection shared by binary codecs. Go values are
+// converted to generic trees and back following encoding/json conventions:
+// json struct tags (name, omitempty, "-"), embedded structs, json.Marshaler
+// and encoding.TextMarshaler (and their Unmarshaler counterparts).
+//
+// Generic tree produced by ToValue consists of nil, bool, int64, uint64,
+// float32, float64, string, []byte, time.Time, []interface{} and *Map.
+// FromValue accepts the same types, but map[string]interface{} instead of *Map
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Map is generic map with ordered keys
+type Map struct {
+	Keys   []string
+	Values []interface{}
+}
+
+func (m *Map) add(key string, val interface{}) {
+	m.Keys = append(m.Keys, key)
+	m.Values = append(m.Values, val)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ToValue convert v into generic tree
+func ToValue(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.IsValid() && rv.Kind() != reflect.Ptr {
+		// make value addressable to use pointer receiver marshalers
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		rv = ptr.Elem()
+	}
+	return toValue(rv)
+}
+
+func toValue(rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil, nil
+	}
+	t := rv.Type()
+	if t == timeType {
+		return rv.Interface().(time.Time), nil
+	}
+	if m, ok := implements(rv, jsonMarshalerType); ok {
+		b, err := m.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		return fromJSON(b)
+	}
+	if m, ok := implements(rv, textMarshalerType); ok {
+		b, err := m.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return toValue(rv.Elem())
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32:
+		return float32(rv.Float()), nil
+	case reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !implementsType(t.Elem(), jsonMarshalerType) && !implementsType(t.Elem(), textMarshalerType) {
+			return append([]byte{}, rv.Bytes()...), nil
+		}
+		return toList(rv)
+	case reflect.Array:
+		return toList(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return toMap(rv)
+	case reflect.Struct:
+		return toStruct(rv)
+	}
+	return nil, fmt.Errorf("codec: unsupported type: %v", t)
+}
+
+func toList(rv reflect.Value) (interface{}, error) {
+	res := make([]interface{}, rv.Len())
+	for idx := range res {
+		item, err := toValue(rv.Index(idx))
+		if err != nil {
+			return nil, err
+		}
+		res[idx] = item
+	}
+	return res, nil
+}
+
+func toMap(rv reflect.Value) (interface{}, error) {
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+	entries := make([]entry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	m := &Map{}
+	for _, e := range entries {
+		val, err := toValue(e.val)
+		if err != nil {
+			return nil, err
+		}
+		m.add(e.key, val)
+	}
+	return m, nil
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("codec: unsupported map key type: %v", k.Type())
+}
+
+func toStruct(rv reflect.Value) (interface{}, error) {
+	m := &Map{}
+	for _, f := range fieldsOf(rv.Type()) {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok || (f.omitEmpty && isEmpty(fv)) {
+			continue
+		}
+		val, err := toValue(fv)
+		if err != nil {
+			return nil, err
+		}
+		m.add(f.name, val)
+	}
+	return m, nil
+}
+
+// fromJSON convert output of json.Marshaler into generic tree
+func fromJSON(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromJSONValue(v), nil
+}
+
+func fromJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for idx := range v {
+			v[idx] = fromJSONValue(v[idx])
+		}
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		m := &Map{}
+		for _, key := range keys {
+			m.add(key, fromJSONValue(v[key]))
+		}
+		return m
+	}
+	return v
+}
+
+func implementsType(t, iface reflect.Type) bool {
+	return t.Implements(iface) || (t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(iface))
+}
+
+// implements return rv (or its address) if it implements iface
+func implements(rv reflect.Value, iface reflect.Type) (reflect.Value, bool) {
+	if rv.Type().Implements(iface) {
+		return rv, true
+	}
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() && rv.Addr().Type().Implements(iface) {
+		return rv.Addr(), true
+	}
+	return reflect.Value{}, false
+}
+
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return rv.IsNil()
+	}
+	return false
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldsCache sync.Map
+
+// fieldsOf return encoded fields of struct type t (like encoding/json)
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for idx := 0; idx < t.NumField(); idx++ {
+			sf := t.Field(idx)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			fi := append(append([]int{}, index...), idx)
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+				walk(ft, fi)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if len(name) == 0 {
+				name = sf.Name
+			}
+			fields = append(fields, field{name: name, index: fi, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")})
+		}
+	}
+	walk(t, nil)
+	// the shallowest field wins on name conflict
+	sort.SliceStable(fields, func(i, j int) bool { return len(fields[i].index) < len(fields[j].index) })
+	seen := map[string]bool{}
+	res := fields[:0]
+	for _, f := range fields {
+		if !seen[f.name] {
+			seen[f.name] = true
+			res = append(res, f)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return lessIndex(res[i].index, res[j].index) })
+	fieldsCache.Store(t, res)
+	return res
+}
+
+func lessIndex(a, b []int) bool {
+	for idx := 0; idx < len(a) && idx < len(b); idx++ {
+		if a[idx] != b[idx] {
+			return a[idx] < b[idx]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// fieldByIndex return field of struct rv, nil embedded pointers are allocated if alloc is set
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for pos, idx := range index {
+		if pos > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !alloc || !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv, true
+}
+
+// FromValue store generic tree v into value pointed by dst
+func FromValue(v interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("codec: non-nil pointer expected, got %T", dst)
+	}
+	return fromValue(v, rv.Elem())
+}
+
+func fromValue(v interface{}, rv reflect.Value) error {
+	if v == nil {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+	if m, ok := v.(*Map); ok {
+		v = m.toMap()
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return fromValue(v, rv.Elem())
+	}
+	if rv.Type() == timeType {
+		t, err := toTime(v)
+		if err == nil {
+			rv.Set(reflect.ValueOf(t))
+		}
+		return err
+	}
+	if u, ok := implements(rv, jsonUnmarshalerType); ok && rv.Kind() != reflect.Interface {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return u.Interface().(json.Unmarshaler).UnmarshalJSON(b)
+	}
+	if u, ok := implements(rv, textUnmarshalerType); ok && rv.Kind() != reflect.Interface {
+		switch s := v.(type) {
+		case string:
+			return u.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		case []byte:
+			return u.Interface().(encoding.TextUnmarshaler).UnmarshalText(s)
+		}
+	}
+	mismatch := func() error {
+		return fmt.Errorf("codec: cannot decode %T into %v", v, rv.Type())
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return mismatch()
+		}
+		rv.Set(reflect.ValueOf(v))
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(v)
+		if !ok || rv.OverflowInt(i) {
+			return mismatch()
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := toUint64(v)
+		if !ok || rv.OverflowUint(u) {
+			return mismatch()
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(v)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetFloat(f)
+	case reflect.String:
+		switch s := v.(type) {
+		case string:
+			rv.SetString(s)
+		case []byte:
+			rv.SetString(string(s))
+		default:
+			return mismatch()
+		}
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			switch b := v.(type) {
+			case []byte:
+				rv.SetBytes(append([]byte{}, b...))
+				return nil
+			case string:
+				decoded, err := base64.StdEncoding.DecodeString(b)
+				if err != nil {
+					return err
+				}
+				rv.SetBytes(decoded)
+				return nil
+			}
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		arr := reflect.MakeSlice(rv.Type(), len(list), len(list))
+		for idx := range list {
+			if err := fromValue(list[idx], arr.Index(idx)); err != nil {
+				return err
+			}
+		}
+		rv.Set(arr)
+	case reflect.Array:
+		if b, ok := v.([]byte); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(rv, reflect.ValueOf(b))
+			return nil
+		}
+		list, ok := v.([]interface{})
+		if !ok || len(list) > rv.Len() {
+			return mismatch()
+		}
+		for idx := range list {
+			if err := fromValue(list[idx], rv.Index(idx)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(m)))
+		}
+		for key, val := range m {
+			k, err := fromMapKey(key, rv.Type().Key())
+			if err != nil {
+				return err
+			}
+			item := reflect.New(rv.Type().Elem()).Elem()
+			if err = fromValue(val, item); err != nil {
+				return err
+			}
+			rv.SetMapIndex(k, item)
+		}
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		fields := fieldsOf(rv.Type())
+		for key, val := range m {
+			f, ok := lookupField(fields, key)
+			if !ok {
+				continue
+			}
+			fv, ok := fieldByIndex(rv, f.index, true)
+			if !ok {
+				continue
+			}
+			if err := fromValue(val, fv); err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+		}
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+func (m *Map) toMap() map[string]interface{} {
+	res := make(map[string]interface{}, len(m.Keys))
+	for idx, key := range m.Keys {
+		val := m.Values[idx]
+		if nested, ok := val.(*Map); ok {
+			val = nested.toMap()
+		}
+		res[key] = val
+	}
+	return res
+}
+
+func lookupField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func fromMapKey(key string, t reflect.Type) (reflect.Value, error) {
+	k := reflect.New(t)
+	if u, ok := k.Interface().(encoding.TextUnmarshaler); ok && t.Kind() != reflect.String {
+		err := u.UnmarshalText([]byte(key))
+		return k.Elem(), err
+	}
+	switch t.Kind() {
+	case reflect.String:
+		k.Elem().SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || k.Elem().OverflowInt(i) {
+			return k.Elem(), fmt.Errorf("codec: invalid map key %q for %v", key, t)
+		}
+		k.Elem().SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || k.Elem().OverflowUint(u) {
+			return k.Elem(), fmt.Errorf("codec: invalid map key %q for %v", key, t)
+		}
+		k.Elem().SetUint(u)
+	default:
+		return k.Elem(), fmt.Errorf("codec: unsupported map key type: %v", t)
+	}
+	return k.Elem(), nil
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	}
+	if f, ok := toFloat64(v); ok {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("codec: cannot decode %T into time.Time", v)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float64:
+		return int64(v), v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64
+	case float32:
+		return toInt64(float64(v))
+	}
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return uint64(v), v >= 0
+	case uint64:
+		return v, true
+	case float64:
+		return uint64(v), v == math.Trunc(v) && v >= 0 && v < math.MaxUint64
+	case float32:
+		return toUint64(float64(v))
+	}
+	return 0, false
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// MaxDepth is max nesting depth of decoded values
+const MaxDepth = 1000
+
+// ErrMaxDepth is returned by decoders when MaxDepth is exceeded
+var ErrMaxDepth = fmt.Errorf("codec: max nesting depth %d exceeded", MaxDepth)
+
+// Key convert decoded map key into string
+func Key(k interface{}) string {
+	switch k := k.(type) {
+	case string:
+		return k
+	case []byte:
+		return string(k)
+	}
+	return fmt.Sprint(k)
+}
@@ -0,0 +1,321 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"encoding/xml"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Names of Commands added to API with first async Command
+const (
+	CommandJobStatus = "job.status"
+	CommandJobResult = "job.result"
+	CommandJobCancel = "job.cancel"
+)
+
+// JobStatus is enum of Job statuses
+type JobStatus string
+
+const (
+	// JobPending means Job waits for free worker
+	JobPending JobStatus = "pending"
+	// JobRunning means Job handler is running
+	JobRunning JobStatus = "running"
+	// JobDone means Job handler finished successfully, Job Result is set
+	JobDone JobStatus = "done"
+	// JobFailed means Job handler returned error, Job Error is set
+	JobFailed JobStatus = "failed"
+	// JobCanceled means Job was canceled by job.cancel
+	JobCanceled JobStatus = "canceled"
+)
+
+// Finished return true if status is final
+func (s JobStatus) Finished() bool {
+	return s == JobDone || s == JobFailed || s == JobCanceled
+}
+
+// Job is state of async Command execution
+type Job struct {
+	XMLName  xml.Name    `json:"-" xml:"job" yaml:"-"`
+	ID       string      `json:"id" xml:"id,attr" yaml:"id"`
+	Command  string      `json:"command" xml:"command,attr" yaml:"command"`
+	Session  string      `json:"session,omitempty" xml:"session,attr,omitempty" yaml:"session,omitempty"`
+	Status   JobStatus   `json:"status" xml:"status,attr" yaml:"status"`
+	Created  time.Time   `json:"created" xml:"created,attr" yaml:"created"`
+	Started  *time.Time  `json:"started,omitempty" xml:"started,attr,omitempty" yaml:"started,omitempty"`
+	Finished *time.Time  `json:"finished,omitempty" xml:"finished,attr,omitempty" yaml:"finished,omitempty"`
+	Result   interface{} `json:"result,omitempty" xml:"result,omitempty" yaml:"result,omitempty"`
+	Error    *Error      `json:"error,omitempty" xml:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// JobStore is storage of Job state. It must be safe for concurrent use
+type JobStore interface {
+	// Save create or replace Job
+	Save(job Job) error
+	// Load return Job by ID, ok is false if Job not found
+	Load(id string) (job Job, ok bool, err error)
+	// Delete remove Job by ID
+	Delete(id string) error
+}
+
+type memoryJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+// NewMemoryJobStore return in-memory JobStore
+func NewMemoryJobStore() JobStore {
+	return &memoryJobStore{jobs: map[string]Job{}}
+}
+
+func (s *memoryJobStore) Save(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *memoryJobStore) Load(id string) (Job, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	return job, ok, nil
+}
+
+func (s *memoryJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// DefaultJobQueue is default JobManager Queue
+const DefaultJobQueue = 1024
+
+// JobManager runs handlers of async Commands on bounded worker pool and
+// keeps their state in Store
+type JobManager struct {
+	Store JobStore
+	// TTL is time finished Job is kept in Store, zero means forever
+	TTL time.Duration
+	// Queue is max count of pending Jobs waiting for free worker.
+	// Submit of more Jobs fails with ErrOverloaded
+	Queue   int
+	sem     chan struct{}
+	pending int64
+	mu      sync.Mutex
+	cancels map[string]stdcontext.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewJobManager is JobManager constructor. Non-positive workers means runtime.NumCPU()
+func NewJobManager(store JobStore, workers int) *JobManager {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &JobManager{
+		Store:   store,
+		Queue:   DefaultJobQueue,
+		sem:     make(chan struct{}, workers),
+		cancels: map[string]stdcontext.CancelFunc{},
+	}
+}
+
+// reserve slot of submitted Job (running or pending), return false if Queue is full
+func (m *JobManager) reserve() bool {
+	if atomic.AddInt64(&m.pending, 1) > int64(cap(m.sem)+m.Queue) {
+		atomic.AddInt64(&m.pending, -1)
+		return false
+	}
+	return true
+}
+
+// Get return Job by ID
+func (m *JobManager) Get(id string) (Job, bool, error) {
+	return m.Store.Load(id)
+}
+
+// Cancel cancel pending or running Job and return its current state.
+// Finished Job is returned as is
+func (m *JobManager) Cancel(id string) (Job, bool, error) {
+	m.mu.Lock()
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+	}
+	m.mu.Unlock()
+	return m.Store.Load(id)
+}
+
+// Wait for all submitted Jobs finished
+func (m *JobManager) Wait() {
+	m.wg.Wait()
+}
+
+// submitJob save pending Job for validated request of c and run its Command
+// Handler (without middleware, which is applied to submission) in background
+// with copy of request. Response Result is set to Job
+func (api *API) submitJob(c *context) error {
+	m := api.Jobs
+	if m == nil {
+		return api.NewError(ErrUnknown, "jobs are not enabled")
+	}
+	if !m.reserve() {
+		return api.NewError(ErrOverloaded, "job queue is full")
+	}
+	job := Job{
+		ID:      uuid.New().String(),
+		Command: c.Command().Name,
+		Session: c.Request().Session,
+		Status:  JobPending,
+		Created: time.Now().UTC(),
+	}
+	if err := m.Store.Save(job); err != nil {
+		atomic.AddInt64(&m.pending, -1)
+		return err
+	}
+	// job outlives request, but keeps its context values (like trace span)
+	ctx, cancel := stdcontext.WithCancel(stdcontext.WithoutCancel(c.StdContext()))
+	m.mu.Lock()
+	m.cancels[job.ID] = cancel
+	m.mu.Unlock()
+	jc := &context{ctx: ctx, api: api, req: c.Request().Clone(), cmd: c.Command()}
+	m.wg.Add(1)
+	go m.run(api, jc, job)
+	c.Response().Result = job
+	return nil
+}
+
+func (m *JobManager) run(api *API, c *context, job Job) {
+	defer m.wg.Done()
+	defer atomic.AddInt64(&m.pending, -1)
+	defer func() {
+		m.mu.Lock()
+		m.cancels[job.ID]()
+		delete(m.cancels, job.ID)
+		m.mu.Unlock()
+		finished := time.Now().UTC()
+		job.Finished = &finished
+		_ = m.Store.Save(job)
+		if m.TTL > 0 {
+			time.AfterFunc(m.TTL, func() { _ = m.Store.Delete(job.ID) })
+		}
+	}()
+	ctx := c.StdContext()
+	select {
+	case m.sem <- struct{}{}:
+		defer func() { <-m.sem }()
+	case <-ctx.Done():
+		job.Status = JobCanceled
+		return
+	}
+	started := time.Now().UTC()
+	job.Status, job.Started = JobRunning, &started
+	_ = m.Store.Save(job)
+	err := m.call(api, c)
+	switch {
+	case ctx.Err() != nil:
+		job.Status = JobCanceled
+	case err != nil:
+		api.handleError(c, err)
+		job.Status, job.Error = JobFailed, c.Response().Error
+	default:
+		job.Status, job.Result = JobDone, c.Response().Result
+	}
+}
+
+// call run Job Command Handler. Panic is recovered into ErrUnknown with
+// PanicError Internal (in Debug mode too, as there is no caller to re-panic to)
+func (m *JobManager) call(api *API, c *context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = api.NewErrorInternal(ErrUnknown, recoveredPanic(r), "panic")
+		}
+	}()
+	if err = api.wrap(c, "sedoc.job", c.Command().Handler)(c); err == nil {
+		err = c.applyPage()
+	}
+	return
+}
+
+// jobOf return Job of "job" argument visible for request session
+func (api *API) jobOf(c Context, get func(string) (Job, bool, error)) (Job, error) {
+	id, _ := c.Request().Arguments["job"].(string)
+	job, ok, err := get(id)
+	if err != nil {
+		return job, c.ErrorInternal(ErrUnknown, err)
+	}
+	if !ok || job.Session != c.Request().Session {
+		return Job{}, c.Error(ErrUnknownJob, id)
+	}
+	return job, nil
+}
+
+func (api *API) jobCommands() Commands {
+	job := Argument{Name: "job", Type: ArgumentTypeString, Required: true, Description: "Job identifier"}
+	status := ResultOf(Job{})
+	status.Remove("result")
+	return Commands{
+		{
+			Name:        CommandJobStatus,
+			Errors:      []int{ErrUnknownJob},
+			Description: "Get async command job status",
+			Arguments:   Arguments{job},
+			Result:      status,
+			Handler: func(c Context) error {
+				job, err := api.jobOf(c, api.Jobs.Get)
+				if err != nil {
+					return err
+				}
+				job.Result = nil
+				c.Response().Result = job
+				return nil
+			},
+		},
+		{
+			Name:        CommandJobResult,
+			Errors:      []int{ErrUnknownJob, ErrJobNotFinished, ErrJobCanceled},
+			Description: "Get async command job result (or error)",
+			Arguments:   Arguments{job},
+			Handler: func(c Context) error {
+				job, err := api.jobOf(c, api.Jobs.Get)
+				if err != nil {
+					return err
+				}
+				switch job.Status {
+				case JobDone:
+					c.Response().Result = job.Result
+					return nil
+				case JobFailed:
+					return job.Error
+				case JobCanceled:
+					return c.Error(ErrJobCanceled, job.ID)
+				}
+				return c.Error(ErrJobNotFinished, job.ID, job.Status)
+			},
+		},
+		{
+			Name:        CommandJobCancel,
+			Errors:      []int{ErrUnknownJob},
+			Description: "Cancel async command job",
+			Arguments:   Arguments{job},
+			Result:      status,
+			Handler: func(c Context) error {
+				if _, err := api.jobOf(c, api.Jobs.Get); err != nil {
+					return err
+				}
+				job, err := api.jobOf(c, api.Jobs.Cancel)
+				if err != nil {
+					return err
+				}
+				job.Result = nil
+				c.Response().Result = job
+				return nil
+			},
+		},
+	}
+}
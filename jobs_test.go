@@ -0,0 +1,176 @@
+package sedoc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newJobsAPI(release <-chan struct{}) *API {
+	a := New()
+	a.Jobs = NewJobManager(NewMemoryJobStore(), 1)
+	a.AddCommand(Command{
+		Name:      "report.build",
+		Kind:      CommandKindAsync,
+		Arguments: Arguments{{Name: "fail", Type: ArgumentTypeBoolean}},
+		Handler: func(c Context) error {
+			select {
+			case <-release:
+			case <-c.StdContext().Done():
+				return c.StdContext().Err()
+			}
+			if c.Request().Arguments["fail"] == true {
+				return errors.New("report failed")
+			}
+			c.Response().Result = InterfaceMap{"rows": 42}
+			return nil
+		},
+	})
+	return a
+}
+
+func submit(t *testing.T, a *API, req *Request) Job {
+	t.Helper()
+	resp := a.Execute(req)
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	job, ok := resp.Result.(Job)
+	if !ok || len(job.ID) == 0 || job.Status != JobPending {
+		t.Fatalf("Execute() result = %v, want pending Job", resp.Result)
+	}
+	return job
+}
+
+func jobCall(a *API, cmd, session, id string) *Response {
+	return a.Execute(&Request{Command: cmd, Session: session, Arguments: InterfaceMap{"job": id}})
+}
+
+func TestAPI_async(t *testing.T) {
+	release := make(chan struct{})
+	a := newJobsAPI(release)
+	for _, name := range []string{CommandJobStatus, CommandJobResult, CommandJobCancel} {
+		if !a.Commands.Contains(name) {
+			t.Fatalf("%s command is not added", name)
+		}
+	}
+	ok := submit(t, a, &Request{Command: "report.build"})
+	failed := submit(t, a, &Request{Command: "report.build", Arguments: InterfaceMap{"fail": true}})
+	if resp := jobCall(a, CommandJobResult, "", ok.ID); resp.Error == nil || resp.Error.Code != ErrJobNotFinished {
+		t.Errorf("job.result of unfinished job error = %v", resp.Error)
+	}
+	close(release)
+	a.Jobs.Wait()
+
+	resp := jobCall(a, CommandJobStatus, "", ok.ID)
+	if job, _ := resp.Result.(Job); resp.Error != nil || job.Status != JobDone || job.Result != nil || job.Finished == nil {
+		t.Errorf("job.status = %v, %v", resp.Result, resp.Error)
+	}
+	resp = jobCall(a, CommandJobResult, "", ok.ID)
+	if m, _ := resp.Result.(InterfaceMap); resp.Error != nil || m["rows"] != 42 {
+		t.Errorf("job.result = %v, %v", resp.Result, resp.Error)
+	}
+	resp = jobCall(a, CommandJobResult, "", failed.ID)
+	if resp.Error == nil || resp.Error.Code != ErrUnknown {
+		t.Errorf("job.result of failed job error = %v", resp.Error)
+	}
+	if resp = jobCall(a, CommandJobStatus, "01234567-89ab-cdef-0123-456789abcdef", ok.ID); resp.Error == nil || resp.Error.Code != ErrUnknownJob {
+		t.Errorf("job.status of another session error = %v", resp.Error)
+	}
+	if resp = jobCall(a, CommandJobStatus, "", "unknown"); resp.Error == nil || resp.Error.Code != ErrUnknownJob {
+		t.Errorf("job.status of unknown job error = %v", resp.Error)
+	}
+}
+
+func TestAPI_asyncCancel(t *testing.T) {
+	a := newJobsAPI(make(chan struct{}))
+	first := submit(t, a, &Request{Command: "report.build"})
+	second := submit(t, a, &Request{Command: "report.build"})
+	ids := []string{first.ID, second.ID}
+	// one of jobs is running, another is pending for free worker
+	for running := false; !running; time.Sleep(time.Millisecond) {
+		for _, id := range ids {
+			job, _, _ := a.Jobs.Get(id)
+			running = running || job.Status == JobRunning
+		}
+	}
+	for _, id := range ids {
+		if resp := jobCall(a, CommandJobCancel, "", id); resp.Error != nil {
+			t.Errorf("job.cancel error = %v", resp.Error)
+		}
+	}
+	a.Jobs.Wait()
+	for _, id := range ids {
+		if resp := jobCall(a, CommandJobResult, "", id); resp.Error == nil || resp.Error.Code != ErrJobCanceled {
+			t.Errorf("job.result of canceled job error = %v", resp.Error)
+		}
+	}
+}
+
+func TestJobManager_TTL(t *testing.T) {
+	a := newJobsAPI(nil)
+	a.Jobs.TTL = time.Millisecond
+	job := submit(t, a, &Request{Command: "report.build"})
+	if resp := jobCall(a, CommandJobCancel, "", job.ID); resp.Error != nil {
+		t.Fatalf("job.cancel error = %v", resp.Error)
+	}
+	a.Jobs.Wait()
+	for {
+		if _, ok, _ := a.Jobs.Get(job.ID); !ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestJobManager_Queue(t *testing.T) {
+	release := make(chan struct{})
+	a := newJobsAPI(release)
+	a.Jobs.Queue = 1
+	submit(t, a, &Request{Command: "report.build"})
+	submit(t, a, &Request{Command: "report.build"})
+	if resp := a.Execute(&Request{Command: "report.build"}); resp.Error == nil || resp.Error.Code != ErrOverloaded {
+		t.Errorf("Execute() of full queue error = %v, want %d", resp.Error, ErrOverloaded)
+	}
+	close(release)
+	a.Jobs.Wait()
+	submit(t, a, &Request{Command: "report.build"})
+	a.Jobs.Wait()
+}
+
+func TestAPI_asyncPanicDebug(t *testing.T) {
+	a := New()
+	a.Debug = true
+	a.AddCommand(Command{Name: "report.panic", Kind: CommandKindAsync, Handler: func(c Context) error { panic("boom") }})
+	job := submit(t, a, &Request{Command: "report.panic"})
+	a.Jobs.Wait()
+	resp := jobCall(a, CommandJobResult, "", job.ID)
+	var perr *PanicError
+	if resp.Error == nil || resp.Error.Code != ErrUnknown || !errors.As(resp.Error.Internal, &perr) {
+		t.Errorf("job.result of panicked job error = %v", resp.Error)
+	}
+}
+
+func TestAPI_asyncMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	a := newJobsAPI(release)
+	var calls int
+	a.Use(IdempotencyWithConfig(IdempotencyConfig{Skipper: func(Context) bool { return false }}), func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			calls++
+			return next(c)
+		}
+	})
+	req := &Request{ID: "1", Command: "report.build", Arguments: InterfaceMap{"fail": false}}
+	first := submit(t, a, req)
+	retry := submit(t, a, req)
+	if retry.ID != first.ID || calls != 1 {
+		t.Errorf("retry job = %s, want %s, middleware calls %d", retry.ID, first.ID, calls)
+	}
+	req.Arguments["fail"] = true
+	close(release)
+	a.Jobs.Wait()
+	if resp := jobCall(a, CommandJobResult, "", first.ID); resp.Error != nil {
+		t.Errorf("job.result error = %v, job request is not copied", resp.Error)
+	}
+}
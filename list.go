@@ -0,0 +1,253 @@
+package sedoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// PagingMode is enum of supported Paging modes
+type PagingMode string
+
+const (
+	// PagingOffset means paging by "count" and "offset" arguments
+	PagingOffset PagingMode = "offset"
+	// PagingCursor means paging by "count" and "cursor" arguments, next cursor is returned in Response Page
+	PagingCursor PagingMode = "cursor"
+)
+
+// Names of list arguments added to Command Arguments by Paging, Sort and Fields
+const (
+	ArgumentNameCount  = "count"
+	ArgumentNameOffset = "offset"
+	ArgumentNameCursor = "cursor"
+	ArgumentNameSort   = "sort"
+	ArgumentNameFields = "fields"
+)
+
+// Paging describes Command pagination
+type Paging struct {
+	XMLName      xml.Name   `json:"-" xml:"paging" yaml:"-"`
+	Mode         PagingMode `json:"mode" xml:"mode,attr" yaml:"mode"`
+	DefaultCount int        `json:"default_count,omitempty" xml:"default_count,attr,omitempty" yaml:"default_count,omitempty"`
+	MaxCount     int        `json:"max_count,omitempty" xml:"max_count,attr,omitempty" yaml:"max_count,omitempty"`
+}
+
+// SortKey is single key of request sort
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Page is parsed request paging, sort and fields selection
+type Page struct {
+	Count  int
+	Offset int
+	Cursor string
+	Sort   []SortKey
+	Fields []string
+}
+
+// PageInfo is Response pagination metadata
+type PageInfo struct {
+	XMLName    xml.Name `json:"-" xml:"page" yaml:"-"`
+	Count      int      `json:"count,omitempty" xml:"count,attr,omitempty" yaml:"count,omitempty"`
+	Offset     int      `json:"offset,omitempty" xml:"offset,attr,omitempty" yaml:"offset,omitempty"`
+	Total      *int     `json:"total,omitempty" xml:"total,attr,omitempty" yaml:"total,omitempty"`
+	NextCursor string   `json:"next_cursor,omitempty" xml:"next_cursor,attr,omitempty" yaml:"next_cursor,omitempty"`
+}
+
+// SetPage set Response Page total (negative total means unknown) and next cursor.
+// Count and offset are filled from request by API Execute
+func (r *Response) SetPage(total int, nextCursor string) {
+	r.Page = &PageInfo{NextCursor: nextCursor}
+	if total >= 0 {
+		r.Page.Total = &total
+	}
+}
+
+// listArguments return Arguments implied by Paging, Sort and Fields
+func (cmd *Command) listArguments() (args Arguments) {
+	if cmd.Paging != nil {
+		desc := "Count of items"
+		if cmd.Paging.MaxCount > 0 {
+			desc = fmt.Sprintf("%s (max %d)", desc, cmd.Paging.MaxCount)
+		}
+		args = append(args, Argument{Name: ArgumentNameCount, Type: ArgumentTypeInteger, Description: desc})
+		if cmd.Paging.Mode == PagingCursor {
+			args = append(args, Argument{Name: ArgumentNameCursor, Type: ArgumentTypeString, Description: "Cursor returned as `next_cursor` of previous page"})
+		} else {
+			args = append(args, Argument{Name: ArgumentNameOffset, Type: ArgumentTypeInteger, Description: "Items offset"})
+		}
+	}
+	if len(cmd.Sort) > 0 {
+		args = append(args, Argument{
+			Name:        ArgumentNameSort,
+			Type:        ArgumentTypeString,
+			Multiple:    true,
+			Description: "Sort keys, field name (prefixed by `-` for descending order) of: " + strings.Join(cmd.Sort, ", "),
+		})
+	}
+	if len(cmd.Fields) > 0 {
+		args = append(args, Argument{
+			Name:        ArgumentNameFields,
+			Type:        ArgumentTypeString,
+			Multiple:    true,
+			Description: "Result fields to return, of: " + strings.Join(cmd.Fields, ", "),
+		})
+	}
+	return
+}
+
+// withListArguments return cmd Arguments with implied by Paging, Sort and Fields (if not declared)
+func (cmd *Command) withListArguments() Arguments {
+	args := cmd.Arguments
+	for _, arg := range cmd.listArguments() {
+		if !args.Contains(arg.Name) {
+			args = append(append(Arguments{}, args...), arg)
+		}
+	}
+	return args
+}
+
+// checkList check validated request list arguments against Paging, Sort and Fields
+func (cmd *Command) checkList(request *Request) error {
+	page := cmd.page(request)
+	invalid := func(name, reason string, val interface{}) error {
+		err := DefaultErrors.Get(ErrInvalidArgumentValue)
+		err.Description = fmt.Sprintf("args: %s (%s): %v", err.Description, name, val)
+		err.Details = []ErrorDetail{fieldDetail("args: ", name, reason)}
+		err.Details[0].Actual = fmt.Sprint(val)
+		return &err
+	}
+	if cmd.Paging != nil {
+		if page.Count < 0 || (cmd.Paging.MaxCount > 0 && page.Count > cmd.Paging.MaxCount) {
+			return invalid(ArgumentNameCount, "out of range", page.Count)
+		}
+		if page.Offset < 0 {
+			return invalid(ArgumentNameOffset, "out of range", page.Offset)
+		}
+	}
+	for _, key := range page.Sort {
+		if !containsString(cmd.Sort, key.Field) {
+			return invalid(ArgumentNameSort, "not allowed", key.Field)
+		}
+	}
+	for _, field := range page.Fields {
+		if !containsString(cmd.Fields, field) {
+			return invalid(ArgumentNameFields, "not allowed", field)
+		}
+	}
+	return nil
+}
+
+// page return Page from validated request
+func (cmd *Command) page(request *Request) (page Page) {
+	args := request.Arguments
+	if cmd.Paging != nil {
+		page.Count = cmd.Paging.DefaultCount
+	}
+	if v, err := parseInteger(args[ArgumentNameCount], false); err == nil {
+		page.Count = v.(int)
+	}
+	if v, err := parseInteger(args[ArgumentNameOffset], false); err == nil {
+		page.Offset = v.(int)
+	}
+	if v, ok := args[ArgumentNameCursor].(string); ok {
+		page.Cursor = v
+	}
+	if v, ok := args[ArgumentNameSort].([]string); ok {
+		for _, s := range v {
+			key := SortKey{Field: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+			key.Field = strings.TrimPrefix(key.Field, "+")
+			page.Sort = append(page.Sort, key)
+		}
+	}
+	if v, ok := args[ArgumentNameFields].([]string); ok {
+		page.Fields = v
+	}
+	return
+}
+
+// Project return v (object or array of objects) with only fields of its
+// JSON representation. Objects are returned as InterfaceMap (array of objects
+// as []InterfaceMap), JSON numbers as int64 or float64. Nil fields means all fields
+func Project(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 || v == nil {
+		return v, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var raw interface{}
+	if err = d.Decode(&raw); err != nil {
+		return nil, err
+	}
+	raw = jsonNumbers(raw)
+	project := func(item interface{}) (InterfaceMap, bool) {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		res := make(InterfaceMap, len(fields))
+		for _, field := range fields {
+			if val, ok := m[field]; ok {
+				res[field] = val
+			}
+		}
+		return res, true
+	}
+	if arr, ok := raw.([]interface{}); ok {
+		maps, objects := make([]InterfaceMap, len(arr)), true
+		for idx := range arr {
+			if m, ok := project(arr[idx]); ok {
+				maps[idx], arr[idx] = m, m
+			} else {
+				objects = false
+			}
+		}
+		if !objects {
+			return arr, nil
+		}
+		return maps, nil
+	}
+	if m, ok := project(raw); ok {
+		return m, nil
+	}
+	return raw, nil
+}
+
+// jsonNumbers replace json.Number in v decoded with UseNumber by int64 (or float64)
+func jsonNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case []interface{}:
+		for idx := range val {
+			val[idx] = jsonNumbers(val[idx])
+		}
+	case map[string]interface{}:
+		for key := range val {
+			val[key] = jsonNumbers(val[key])
+		}
+	}
+	return v
+}
+
+func containsString(arr []string, s string) bool {
+	for _, item := range arr {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
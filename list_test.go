@@ -0,0 +1,170 @@
+package sedoc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type listUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func newListAPI(got *Page) *API {
+	a := New()
+	a.AddCommand(Command{
+		Name:   "user.list",
+		Paging: &Paging{Mode: PagingOffset, DefaultCount: 10, MaxCount: 100},
+		Sort:   []string{"id", "name"},
+		Fields: []string{"id", "name", "email"},
+		Handler: func(c Context) error {
+			*got = c.Page()
+			c.Response().Result = []listUser{{1, "foo", "foo@example.com"}, {2, "bar", "bar@example.com"}}
+			c.Response().SetPage(2, "")
+			return nil
+		},
+	})
+	a.AddCommand(Command{
+		Name:   "user.feed",
+		Paging: &Paging{Mode: PagingCursor},
+		Handler: func(c Context) error {
+			*got = c.Page()
+			c.Response().SetPage(-1, "next")
+			return nil
+		},
+	})
+	return a
+}
+
+func TestCommand_listArguments(t *testing.T) {
+	a := newListAPI(&Page{})
+	list := a.GetCommand("user.list")
+	for _, name := range []string{ArgumentNameCount, ArgumentNameOffset, ArgumentNameSort, ArgumentNameFields} {
+		if !list.Arguments.Contains(name) {
+			t.Errorf("user.list arguments do not contain %s", name)
+		}
+	}
+	feed := a.GetCommand("user.feed")
+	if !feed.Arguments.Contains(ArgumentNameCursor) || feed.Arguments.Contains(ArgumentNameOffset) {
+		t.Errorf("user.feed arguments = %v", feed.Arguments)
+	}
+	declared := Command{Paging: &Paging{}, Arguments: Arguments{{Name: ArgumentNameCount, Type: ArgumentTypeInteger, Description: "custom"}}}
+	if args := declared.withListArguments(); len(args) != 2 || args[0].Description != "custom" {
+		t.Errorf("withListArguments() = %v", args)
+	}
+}
+
+func TestAPI_Execute_list(t *testing.T) {
+	got := Page{}
+	a := newListAPI(&got)
+	total := 2
+	tests := []struct {
+		name     string
+		req      *Request
+		wantPage Page
+		wantInfo *PageInfo
+		wantCode int
+	}{
+		{"defaults", &Request{Command: "user.list"}, Page{Count: 10}, &PageInfo{Count: 10, Total: &total}, 0},
+		{"offset", &Request{Command: "user.list", Arguments: InterfaceMap{"count": "5", "offset": 5.0, "sort": []interface{}{"-id", "name"}}},
+			Page{Count: 5, Offset: 5, Sort: []SortKey{{"id", true}, {"name", false}}}, &PageInfo{Count: 5, Offset: 5, Total: &total}, 0},
+		{"cursor", &Request{Command: "user.feed", Arguments: InterfaceMap{"count": 3, "cursor": "abc"}},
+			Page{Count: 3, Cursor: "abc"}, &PageInfo{Count: 3, NextCursor: "next"}, 0},
+		{"max count", &Request{Command: "user.list", Arguments: InterfaceMap{"count": 101}}, Page{}, nil, ErrInvalidArgumentValue},
+		{"negative offset", &Request{Command: "user.list", Arguments: InterfaceMap{"offset": -1}}, Page{}, nil, ErrInvalidArgumentValue},
+		{"sort not allowed", &Request{Command: "user.list", Arguments: InterfaceMap{"sort": []interface{}{"email"}}}, Page{}, nil, ErrInvalidArgumentValue},
+		{"fields not allowed", &Request{Command: "user.list", Arguments: InterfaceMap{"fields": []interface{}{"password"}}}, Page{}, nil, ErrInvalidArgumentValue},
+		{"offset in cursor mode", &Request{Command: "user.feed", Arguments: InterfaceMap{"offset": 1}}, Page{}, nil, ErrUnknownArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = Page{}
+			resp := a.Execute(tt.req)
+			code := 0
+			if resp.Error != nil {
+				code = resp.Error.Code
+			}
+			if code != tt.wantCode {
+				t.Fatalf("Execute() error = %v, want code %d", resp.Error, tt.wantCode)
+			}
+			if !reflect.DeepEqual(got, tt.wantPage) {
+				t.Errorf("Context.Page() = %+v, want %+v", got, tt.wantPage)
+			}
+			if !reflect.DeepEqual(resp.Page, tt.wantInfo) {
+				t.Errorf("Response.Page = %+v, want %+v", resp.Page, tt.wantInfo)
+			}
+		})
+	}
+}
+
+func TestAPI_Execute_fields(t *testing.T) {
+	a := newListAPI(&Page{})
+	resp := a.Execute(&Request{Command: "user.list", Arguments: InterfaceMap{"fields": []interface{}{"id", "name"}}})
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	want := []InterfaceMap{
+		{"id": int64(1), "name": "foo"},
+		{"id": int64(2), "name": "bar"},
+	}
+	if !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("Response.Result = %v, want %v", resp.Result, want)
+	}
+	b, err := xml.Marshal(resp)
+	if err != nil || !strings.Contains(string(b), `<result id="1" name="foo"></result>`) {
+		t.Errorf("xml.Marshal() = %s, %v", b, err)
+	}
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      interface{}
+		fields []string
+		want   interface{}
+	}{
+		{"no fields", listUser{ID: 1}, nil, listUser{ID: 1}},
+		{"object", &listUser{ID: 1, Name: "foo"}, []string{"name", "unknown"}, InterfaceMap{"name": "foo"}},
+		{"array", []listUser{{ID: 1, Name: "foo"}}, []string{"id"}, []InterfaceMap{{"id": int64(1)}}},
+		{"mixed array", []interface{}{listUser{ID: 1}, 2.5}, []string{"id"}, []interface{}{InterfaceMap{"id": int64(1)}, 2.5}},
+		{"scalar", 5, []string{"name"}, int64(5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Project(tt.v, tt.fields)
+			if err != nil {
+				t.Fatalf("Project() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Project() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPI_Execute_listHTTP(t *testing.T) {
+	got := Page{}
+	a := newListAPI(&got)
+	a.AddCommand(Command{
+		Name:    "user.all",
+		Paging:  &Paging{Mode: PagingOffset, MaxCount: 1000},
+		Handler: func(c Context) error { got = c.Page(); return nil },
+	})
+	for _, count := range []int{200, 1000} {
+		got = Page{}
+		hr := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/?count=%d&offset=300", count), nil)
+		resp := a.Execute(a.RequestFromURL(&Request{Command: "user.all"}, hr.URL))
+		if resp.Error != nil {
+			t.Fatalf("Execute(count=%d) error = %v", count, resp.Error)
+		}
+		if want := (Page{Count: count, Offset: 300}); !reflect.DeepEqual(got, want) {
+			t.Errorf("Context.Page() = %+v, want %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sedoc
+
+import (
+	"log/slog"
+	"time"
+)
+
+// RedactedValue replace values of Sensitive Arguments in logs and Response Arguments
+const RedactedValue = "[REDACTED]"
+
+// redact return copy of params with values of Sensitive args replaced by RedactedValue.
+// Where keys with operator suffix are matched by field name
+func (cmd *Command) redact(params InterfaceMap, args Arguments) InterfaceMap {
+	if params == nil {
+		return nil
+	}
+	var res InterfaceMap
+	for key := range params {
+		field, _, _ := ParseWhereKey(key)
+		if arg, err := args.Get(field); err != nil || !arg.Sensitive {
+			continue
+		}
+		if res == nil {
+			res = make(InterfaceMap, len(params))
+			for k, v := range params {
+				res[k] = v
+			}
+		}
+		res[key] = RedactedValue
+	}
+	if res == nil {
+		return params
+	}
+	return res
+}
+
+// ErrorLevel return log level by Error class: Info without error, Error for
+// ErrUnknown and ErrInvalidResult (server faults), Warn for others
+// (invalid requests, limits and application errors)
+func ErrorLevel(err *Error) slog.Level {
+	switch {
+	case err == nil || err.Code == 0:
+		return slog.LevelInfo
+	case err.Code == ErrUnknown || err.Code == ErrInvalidResult:
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// LoggerConfig is Logger middleware config
+type LoggerConfig struct {
+	// Logger is destination of records, slog.Default() by default
+	Logger *slog.Logger
+	// Level return record level by error, ErrorLevel by default
+	Level func(*Error) slog.Level
+	// Arguments enable logging of request args, where and set (Sensitive are redacted)
+	Arguments bool
+	// Skipper return true for requests which are not logged
+	Skipper func(Context) bool
+}
+
+// Logger return middleware which logs each execution by logger.
+// It should be added by API Pre to log invalid requests too
+func Logger(logger *slog.Logger) MiddlewareFunc {
+	return LoggerWithConfig(LoggerConfig{Logger: logger})
+}
+
+// LoggerWithConfig return Logger middleware with config
+func LoggerWithConfig(config LoggerConfig) MiddlewareFunc {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.Level == nil {
+		config.Level = ErrorLevel
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			start := time.Now()
+			// panic is logged and re-panicked to be recovered by API
+			defer func() {
+				if r := recover(); r != nil {
+					perr := recoveredPanic(r)
+					serr := DefaultErrors.Get(ErrUnknown)
+					serr.Internal = perr
+					logExecution(config, c, start, &serr)
+					panic(perr)
+				}
+			}()
+			err := next(c)
+			var serr *Error
+			if err != nil {
+				var ok bool
+				if serr, ok = asError(err); !ok {
+					serr = &Error{Code: ErrUnknown, Internal: err}
+				}
+			}
+			logExecution(config, c, start, serr)
+			return err
+		}
+	}
+}
+
+// logExecution log execution of c finished with serr (nil if succeeded)
+func logExecution(config LoggerConfig, c Context, start time.Time, serr *Error) {
+	level := config.Level(serr)
+	ctx := c.StdContext()
+	if !config.Logger.Enabled(ctx, level) {
+		return
+	}
+	req, cmd := c.Request(), c.Command()
+	attrs := []slog.Attr{
+		slog.String("command", req.Command),
+		slog.String("id", req.ID),
+		slog.String("session", req.Session),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if serr != nil {
+		attrs = append(attrs, slog.Int("code", serr.Code), slog.String("error", serr.Description))
+		if serr.Internal != nil {
+			attrs = append(attrs, slog.String("internal", serr.Internal.Error()))
+		}
+	}
+	if config.Arguments {
+		where := make([]interface{}, len(req.Where))
+		for idx := range req.Where {
+			where[idx] = cmd.redact(req.Where[idx], cmd.Where)
+		}
+		attrs = append(attrs,
+			slog.Any("args", cmd.redact(req.Arguments, cmd.withListArguments())),
+			slog.Any("where", where),
+			slog.Any("set", cmd.redact(req.Set, cmd.Set)),
+		)
+	}
+	config.Logger.LogAttrs(ctx, level, "execute", attrs...)
+}
@@ -0,0 +1,91 @@
+package sedoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newLoggingAPI(buf *bytes.Buffer) *API {
+	a := New()
+	a.Pre(LoggerWithConfig(LoggerConfig{
+		Logger:    slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Arguments: true,
+	}))
+	a.AddCommand(Command{
+		Name:      "user.signin",
+		Arguments: Arguments{{Name: "login", Type: ArgumentTypeString}, {Name: "password", Type: ArgumentTypeString, Sensitive: true}},
+		Set:       Arguments{{Name: "password", Type: ArgumentTypeString, Sensitive: true}},
+		Handler: func(c Context) error {
+			c.Response().Arguments = c.Request().Arguments
+			switch c.Request().Arguments["login"] {
+			case "fail":
+				return errors.New("database is down")
+			case "panic":
+				panic("database is down")
+			}
+			return nil
+		},
+	})
+	return a
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *Request
+		wantLevel string
+		wantCode  float64
+	}{
+		{"ok", &Request{ID: "1", Command: "user.signin", Arguments: InterfaceMap{"login": "foo", "password": "secret"}, Set: InterfaceMap{"password": "secret"}}, "INFO", 0},
+		{"invalid request", &Request{ID: "2", Command: "user.signin", Arguments: InterfaceMap{"password": "secret", "unknown": 1}}, "WARN", ErrUnknownArgument},
+		{"unknown command", &Request{ID: "3", Command: "unknown"}, "WARN", ErrUnknownCommand},
+		{"internal", &Request{ID: "4", Command: "user.signin", Arguments: InterfaceMap{"login": "fail", "password": "secret"}}, "ERROR", ErrUnknown},
+		{"panic", &Request{ID: "5", Command: "user.signin", Arguments: InterfaceMap{"login": "panic", "password": "secret"}}, "ERROR", ErrUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			a := newLoggingAPI(buf)
+			resp := a.Execute(tt.req)
+			if strings.Contains(buf.String(), "secret") {
+				t.Errorf("log contains sensitive value: %s", buf.String())
+			}
+			if v, ok := resp.Arguments["password"]; ok && v != RedactedValue {
+				t.Errorf("Response.Arguments password = %v", v)
+			}
+			if _, ok := tt.req.Arguments["password"]; ok && tt.req.Arguments["password"] != "secret" {
+				t.Error("request arguments are modified")
+			}
+			record := map[string]interface{}{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("invalid log record %s: %v", buf.String(), err)
+			}
+			if record["level"] != tt.wantLevel || record["id"] != tt.req.ID || record["command"] != tt.req.Command {
+				t.Errorf("log record = %v", record)
+			}
+			if code, _ := record["code"].(float64); code != tt.wantCode {
+				t.Errorf("log record code = %v, want %v", record["code"], tt.wantCode)
+			}
+			if tt.wantCode == ErrUnknown && !strings.Contains(buf.String(), "database is down") {
+				t.Errorf("log record does not contain internal error: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestCommand_redact(t *testing.T) {
+	cmd := Command{}
+	args := Arguments{{Name: "password", Sensitive: true}, {Name: "login"}}
+	params := InterfaceMap{"login": "foo", "password[ne]": "bar"}
+	got := cmd.redact(params, args)
+	if got["login"] != "foo" || got["password[ne]"] != RedactedValue || params["password[ne]"] != "bar" {
+		t.Errorf("redact() = %v, params %v", got, params)
+	}
+	if params := (InterfaceMap{"login": "foo"}); cmd.redact(params, args)["login"] != "foo" {
+		t.Error("redact() changed not sensitive value")
+	}
+}
@@ -0,0 +1,66 @@
+package sedoc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+// Meta is Response metadata: execution duration, server info and warnings
+type Meta struct {
+	XMLName  xml.Name       `json:"-" xml:"meta" yaml:"-"`
+	Duration types.Duration `json:"duration" xml:"duration,attr" yaml:"duration"`
+	Server   string         `json:"server,omitempty" xml:"server,attr,omitempty" yaml:"server,omitempty"`
+	Version  string         `json:"version,omitempty" xml:"version,attr,omitempty" yaml:"version,omitempty"`
+	Warnings []string       `json:"warnings,omitempty" xml:"warning,omitempty" yaml:"warnings,omitempty"`
+}
+
+// Warn add non-fatal warning to Response Meta
+func (r *Response) Warn(format string, a ...interface{}) {
+	if r.Meta == nil {
+		r.Meta = &Meta{}
+	}
+	r.Meta.Warnings = append(r.Meta.Warnings, fmt.Sprintf(format, a...))
+}
+
+// deprecationWarnings return warnings about deprecated arguments used by request
+func (cmd *Command) deprecationWarnings(request *Request) (warnings []string) {
+	check := func(params InterfaceMap, args Arguments, prefix string) {
+		names := make([]string, 0, len(params))
+		for key := range params {
+			field, _, _ := ParseWhereKey(key)
+			names = append(names, field)
+		}
+		sort.Strings(names)
+		for idx, name := range names {
+			if idx > 0 && names[idx-1] == name {
+				continue
+			}
+			if arg, err := args.Get(name); err == nil && len(arg.Deprecated) > 0 {
+				warnings = append(warnings, fmt.Sprintf("%sargument %s is deprecated: %s", prefix, name, arg.Deprecated))
+			}
+		}
+	}
+	check(request.Arguments, cmd.Arguments, "args: ")
+	check(request.Set, cmd.Set, "set: ")
+	for idx, where := range request.Where {
+		check(where, cmd.Where, fmt.Sprintf("where[%d]: ", idx))
+	}
+	return
+}
+
+// fillMeta fill Response Meta with execution duration and server info
+func (api *API) fillMeta(resp *Response, start time.Time) {
+	if !api.EnableMeta && resp.Meta == nil {
+		return
+	}
+	if resp.Meta == nil {
+		resp.Meta = &Meta{}
+	}
+	resp.Meta.Duration = types.Duration(time.Since(start))
+	resp.Meta.Server = api.Server
+	resp.Meta.Version = api.Version
+}
@@ -0,0 +1,92 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nsemikov/go-sedoc/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func newMetaAPI() *API {
+	a := New()
+	a.Server, a.Version = "node-1", "1.2.3"
+	a.AddCommand(Command{
+		Name:      "user.get",
+		Arguments: Arguments{{Name: "id", Type: ArgumentTypeInteger}, {Name: "uid", Type: ArgumentTypeInteger, Deprecated: "use id"}},
+		Where:     Arguments{{Name: "created_later", Type: ArgumentTypeTime, Deprecated: "use created[gt]"}},
+		Handler: func(c Context) error {
+			if c.Request().Arguments["id"] == 0 {
+				c.Warn("id %d is reserved", 0)
+			}
+			return nil
+		},
+	})
+	return a
+}
+
+func TestAPI_Execute_meta(t *testing.T) {
+	tests := []struct {
+		name         string
+		enable       bool
+		req          *Request
+		wantMeta     bool
+		wantWarnings []string
+	}{
+		{"disabled", false, &Request{Command: "user.get", Arguments: InterfaceMap{"id": 1}}, false, nil},
+		{"enabled", true, &Request{Command: "user.get", Arguments: InterfaceMap{"id": 1}}, true, nil},
+		{"handler warning", false, &Request{Command: "user.get", Arguments: InterfaceMap{"id": 0}}, true, []string{"id 0 is reserved"}},
+		{"deprecated", true, &Request{Command: "user.get", Arguments: InterfaceMap{"uid": 1}, Where: []InterfaceMap{{"created_later": "2018-10-16T09:58:03Z"}}},
+			true, []string{"args: argument uid is deprecated: use id", "where[0]: argument created_later is deprecated: use created[gt]"}},
+		{"unknown command", true, &Request{Command: "unknown"}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newMetaAPI()
+			a.EnableMeta = tt.enable
+			resp := a.Execute(tt.req)
+			if (resp.Meta != nil) != tt.wantMeta {
+				t.Fatalf("Response.Meta = %+v, want meta %v", resp.Meta, tt.wantMeta)
+			}
+			if resp.Meta == nil {
+				return
+			}
+			if resp.Meta.Server != "node-1" || resp.Meta.Version != "1.2.3" || resp.Meta.Duration <= 0 {
+				t.Errorf("Response.Meta = %+v", resp.Meta)
+			}
+			if !reflect.DeepEqual(resp.Meta.Warnings, tt.wantWarnings) {
+				t.Errorf("Response.Meta.Warnings = %q, want %q", resp.Meta.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestMeta_marshal(t *testing.T) {
+	resp := &Response{Command: "foo", Meta: &Meta{Duration: types.Duration(1500000), Server: "node-1", Version: "1.2.3", Warnings: []string{"foo", "bar"}}}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"meta":{"duration":"1.5ms","server":"node-1","version":"1.2.3","warnings":["foo","bar"]}`; !strings.Contains(string(b), want) {
+		t.Errorf("json = %s, want %s", b, want)
+	}
+	if b, err = xml.Marshal(resp); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<meta duration="1.5ms" server="node-1" version="1.2.3"><warning>foo</warning><warning>bar</warning></meta>`; !strings.Contains(string(b), want) {
+		t.Errorf("xml = %s, want %s", b, want)
+	}
+	if b, err = yaml.Marshal(resp); err != nil {
+		t.Fatal(err)
+	}
+	got := Response{}
+	if err = yaml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Meta, resp.Meta) {
+		t.Errorf("yaml roundtrip Meta = %+v, want %+v", got.Meta, resp.Meta)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Package metrics records per Command request counts, latency histograms,
+error counts by Error Code and in-flight gauges of sedoc API and exposes them
+in Prometheus text exposition format (by http.Handler or sedoc Command).
+
+Label values are bounded: requests of not registered commands are counted
+with command label "unknown", error codes not registered in API Errors are
+counted with code label "other". Registered commands and error codes are
+snapshot by New and Refresh, so Refresh should be called once API is set up.
+*/
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+)
+
+// CommandName is name of Command returned by Metrics Command
+const CommandName = "metrics"
+
+// Label values used for not registered commands and error codes
+const (
+	UnknownCommand = "unknown"
+	OtherCode      = "other"
+)
+
+// DefaultBuckets is default latency histogram buckets (seconds)
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type series struct {
+	requests int64
+	inFlight int64
+	errors   map[string]int64
+	bounds   []float64
+	buckets  []int64
+	sum      float64
+}
+
+// Metrics of sedoc API. It is safe for concurrent use
+type Metrics struct {
+	// Namespace is prefix of metric names, "sedoc" by default
+	Namespace string
+	// Buckets is latency histogram upper bounds in seconds, DefaultBuckets by
+	// default. Series keep buckets they are created with
+	Buckets  []float64
+	api      *sedoc.API
+	mu       sync.Mutex
+	series   map[string]*series
+	commands map[string]bool
+	codes    map[int]bool
+}
+
+// New is Metrics constructor
+func New(api *sedoc.API) *Metrics {
+	m := &Metrics{
+		Namespace: "sedoc",
+		Buckets:   DefaultBuckets,
+		api:       api,
+		series:    map[string]*series{},
+	}
+	m.Refresh()
+	return m
+}
+
+// Refresh snapshot API Commands names and Errors codes used as label values.
+// It must not be called concurrently with API AddCommand or RemoveCommand
+func (m *Metrics) Refresh() {
+	commands, codes := map[string]bool{}, map[int]bool{}
+	for _, cmd := range m.api.Commands {
+		commands[cmd.Name] = true
+	}
+	for _, err := range m.api.Errors {
+		codes[err.Code] = true
+	}
+	m.mu.Lock()
+	m.commands, m.codes = commands, codes
+	m.mu.Unlock()
+}
+
+// Middleware return middleware which records executions. It should be added
+// by API Pre to count invalid requests too
+func (m *Metrics) Middleware() sedoc.MiddlewareFunc {
+	return func(next sedoc.HandlerFunc) sedoc.HandlerFunc {
+		return func(c sedoc.Context) error {
+			m.mu.Lock()
+			s := m.get(m.commandLabel(c.Request().Command))
+			s.inFlight++
+			m.mu.Unlock()
+			start := time.Now()
+			// panic of next is recovered by API into ErrUnknown, so it is
+			// recorded as ErrUnknown error
+			err, returned := error(nil), false
+			defer func() {
+				elapsed := time.Since(start).Seconds()
+				m.mu.Lock()
+				defer m.mu.Unlock()
+				s.inFlight--
+				s.requests++
+				s.sum += elapsed
+				s.buckets[sort.SearchFloat64s(s.bounds, elapsed)]++
+				if !returned {
+					s.errors[m.codeLabel(&sedoc.Error{Code: sedoc.ErrUnknown})]++
+				} else if err != nil {
+					s.errors[m.codeLabel(err)]++
+				}
+			}()
+			err = next(c)
+			returned = true
+			return err
+		}
+	}
+}
+
+func (m *Metrics) get(name string) *series {
+	s := m.series[name]
+	if s == nil {
+		// buckets are snapshot, so Buckets change affects new series only
+		bounds := append([]float64{}, m.Buckets...)
+		s = &series{errors: map[string]int64{}, bounds: bounds, buckets: make([]int64, len(bounds)+1)}
+		m.series[name] = s
+	}
+	return s
+}
+
+// commandLabel return label of command name, m.mu must be locked
+func (m *Metrics) commandLabel(name string) string {
+	if m.commands[name] {
+		return name
+	}
+	return UnknownCommand
+}
+
+// codeLabel return label of err code, m.mu must be locked
+func (m *Metrics) codeLabel(err error) string {
+	code := sedoc.ErrUnknown
+	var serr *sedoc.Error
+	if errors.As(err, &serr) {
+		code = serr.Code
+	}
+	if m.codes[code] {
+		return strconv.Itoa(code)
+	}
+	return OtherCode
+}
+
+// WriteTo write metrics in Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	buf := &bytes.Buffer{}
+	ns := m.Namespace
+	m.mu.Lock()
+	names := make([]string, 0, len(m.series))
+	for name := range m.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	family(buf, ns+"_requests_total", "counter", "Total count of executed requests.")
+	for _, name := range names {
+		sample(buf, ns+"_requests_total", m.series[name].requests, "command", name)
+	}
+	family(buf, ns+"_requests_in_flight", "gauge", "Count of requests being executed.")
+	for _, name := range names {
+		sample(buf, ns+"_requests_in_flight", m.series[name].inFlight, "command", name)
+	}
+	family(buf, ns+"_errors_total", "counter", "Total count of failed requests by error code.")
+	for _, name := range names {
+		codes := make([]string, 0, len(m.series[name].errors))
+		for code := range m.series[name].errors {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			sample(buf, ns+"_errors_total", m.series[name].errors[code], "command", name, "code", code)
+		}
+	}
+	family(buf, ns+"_request_duration_seconds", "histogram", "Request execution latency.")
+	for _, name := range names {
+		s := m.series[name]
+		var cumulative int64
+		for idx, le := range s.bounds {
+			cumulative += s.buckets[idx]
+			sample(buf, ns+"_request_duration_seconds_bucket", cumulative, "command", name, "le", formatFloat(le))
+		}
+		sample(buf, ns+"_request_duration_seconds_bucket", s.requests, "command", name, "le", "+Inf")
+		sample(buf, ns+"_request_duration_seconds_sum", s.sum, "command", name)
+		sample(buf, ns+"_request_duration_seconds_count", s.requests, "command", name)
+	}
+	m.mu.Unlock()
+
+	stats := m.api.Stats()
+	family(buf, ns+"_concurrency_queued", "gauge", "Count of requests waiting for global concurrency limit.")
+	sample(buf, ns+"_concurrency_queued", stats.Concurrency.Queued)
+	family(buf, ns+"_concurrency_shed_total", "counter", "Total count of requests shed by global concurrency limit.")
+	sample(buf, ns+"_concurrency_shed_total", stats.Concurrency.Shed)
+	if len(stats.CommandsConcurrency) > 0 {
+		names = names[:0]
+		for name := range stats.CommandsConcurrency {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		family(buf, ns+"_command_concurrency_queued", "gauge", "Count of requests waiting for command concurrency limit.")
+		for _, name := range names {
+			sample(buf, ns+"_command_concurrency_queued", stats.CommandsConcurrency[name].Queued, "command", name)
+		}
+		family(buf, ns+"_command_concurrency_shed_total", "counter", "Total count of requests shed by command concurrency limit.")
+		for _, name := range names {
+			sample(buf, ns+"_command_concurrency_shed_total", stats.CommandsConcurrency[name].Shed, "command", name)
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP implements http.Handler
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// Command return Command which Result is metrics in Prometheus text exposition format
+func (m *Metrics) Command() sedoc.Command {
+	return sedoc.Command{
+		Name:        CommandName,
+		Description: "Get API metrics in Prometheus text exposition format",
+		Result:      sedoc.Arguments{{Name: "result", Type: sedoc.ArgumentTypeString, Description: "Metrics text"}},
+		Handler: func(c sedoc.Context) error {
+			buf := &strings.Builder{}
+			if _, err := m.WriteTo(buf); err != nil {
+				return c.ErrorInternal(sedoc.ErrUnknown, err)
+			}
+			c.Response().Result = buf.String()
+			return nil
+		},
+	}
+}
+
+func family(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sample(buf *bytes.Buffer, name string, value interface{}, labels ...string) {
+	buf.WriteString(name)
+	if len(labels) > 0 {
+		buf.WriteByte('{')
+		for idx := 0; idx < len(labels); idx += 2 {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%s=\"%s\"", labels[idx], escape(labels[idx+1]))
+		}
+		buf.WriteByte('}')
+	}
+	switch v := value.(type) {
+	case float64:
+		fmt.Fprintf(buf, " %s\n", formatFloat(v))
+	default:
+		fmt.Fprintf(buf, " %v\n", v)
+	}
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,136 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+	"github.com/nsemikov/go-sedoc/metrics"
+)
+
+func newAPI() (*sedoc.API, *metrics.Metrics) {
+	api := sedoc.New()
+	m := metrics.New(api)
+	m.Buckets = []float64{0.5, 1}
+	api.Pre(m.Middleware())
+	api.AddCommand(m.Command())
+	api.AddCommand(sedoc.Command{
+		Name:      "user.get",
+		Arguments: sedoc.Arguments{{Name: "fail", Type: sedoc.ArgumentTypeString}},
+		Handler: func(c sedoc.Context) error {
+			switch c.Request().Arguments["fail"] {
+			case "internal":
+				return errors.New("boom")
+			case "custom":
+				return &sedoc.Error{Code: 1000}
+			case "panic":
+				panic("boom")
+			}
+			return nil
+		},
+	})
+	m.Refresh()
+	return api, m
+}
+
+func TestMetrics(t *testing.T) {
+	api, m := newAPI()
+	for _, req := range []*sedoc.Request{
+		{Command: "user.get"},
+		{Command: "user.get"},
+		{Command: "user.get", Arguments: sedoc.InterfaceMap{"fail": "internal"}},
+		{Command: "user.get", Arguments: sedoc.InterfaceMap{"fail": "custom"}},
+		{Command: "user.get", Arguments: sedoc.InterfaceMap{"unknown": "1"}},
+		{Command: "random.1"},
+		{Command: "random.2"},
+	} {
+		api.Execute(req)
+	}
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %s", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE sedoc_requests_total counter\n",
+		`sedoc_requests_total{command="user.get"} 5` + "\n",
+		`sedoc_requests_total{command="unknown"} 2` + "\n",
+		`sedoc_errors_total{command="unknown",code="3"} 2` + "\n",
+		`sedoc_errors_total{command="user.get",code="1"} 1` + "\n",
+		`sedoc_errors_total{command="user.get",code="7"} 1` + "\n",
+		`sedoc_errors_total{command="user.get",code="other"} 1` + "\n",
+		`sedoc_requests_in_flight{command="user.get"} 0` + "\n",
+		"# TYPE sedoc_request_duration_seconds histogram\n",
+		`sedoc_request_duration_seconds_bucket{command="user.get",le="0.5"} 5` + "\n",
+		`sedoc_request_duration_seconds_bucket{command="user.get",le="+Inf"} 5` + "\n",
+		`sedoc_request_duration_seconds_count{command="user.get"} 5` + "\n",
+		"sedoc_concurrency_shed_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "random") {
+		t.Errorf("metrics contain not registered command:\n%s", body)
+	}
+}
+
+func TestMetrics_Command(t *testing.T) {
+	api, _ := newAPI()
+	api.Execute(&sedoc.Request{Command: "user.get"})
+	resp := api.Execute(&sedoc.Request{Command: metrics.CommandName})
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	text, _ := resp.Result.(string)
+	if !strings.Contains(text, `sedoc_requests_total{command="user.get"} 1`) || !strings.Contains(text, `sedoc_requests_in_flight{command="metrics"} 1`) {
+		t.Errorf("metrics command result = %s", text)
+	}
+}
+
+func TestMetrics_Refresh(t *testing.T) {
+	api, m := newAPI()
+	api.AddCommand(sedoc.Command{Name: "user.list", Handler: func(c sedoc.Context) error { return nil }})
+	api.Execute(&sedoc.Request{Command: "user.list"})
+	m.Refresh()
+	api.Execute(&sedoc.Request{Command: "user.list"})
+	buf := &strings.Builder{}
+	if _, err := m.WriteTo(buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	for _, want := range []string{
+		`sedoc_requests_total{command="unknown"} 1` + "\n",
+		`sedoc_requests_total{command="user.list"} 1` + "\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestMetrics_panic(t *testing.T) {
+	api, m := newAPI()
+	resp := api.Execute(&sedoc.Request{Command: "user.get", Arguments: sedoc.InterfaceMap{"fail": "panic"}})
+	if resp.Error == nil || resp.Error.Code != sedoc.ErrUnknown {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	m.Buckets = []float64{0.1}
+	api.Execute(&sedoc.Request{Command: "user.get"})
+	buf := &strings.Builder{}
+	if _, err := m.WriteTo(buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	for _, want := range []string{
+		`sedoc_requests_total{command="user.get"} 2` + "\n",
+		`sedoc_requests_in_flight{command="user.get"} 0` + "\n",
+		`sedoc_errors_total{command="user.get",code="1"} 1` + "\n",
+		`sedoc_request_duration_seconds_bucket{command="user.get",le="0.5"} 2` + "\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package msgpack_test
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	sedoc "github.com/nsemikov/go-sedoc"
+	"github.com/nsemikov/go-sedoc/msgpack"
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"nil", nil, "c0"},
+		{"true", true, "c3"},
+		{"fixint", 1, "01"},
+		{"negative fixint", -1, "ff"},
+		{"int8", -33, "d0df"},
+		{"uint8", 128, "cc80"},
+		{"uint16", 256, "cd0100"},
+		{"int64", int64(math.MinInt64), "d38000000000000000"},
+		{"uint64", uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{"float32", float32(1.5), "ca3fc00000"},
+		{"float64", 1.5, "cb3ff8000000000000"},
+		{"fixstr", "a", "a161"},
+		{"bin", []byte{1}, "c40101"},
+		{"array", []int{1, 2}, "920102"},
+		{"map", map[string]int{"b": 2, "a": 1}, "82a16101a16202"},
+		{"timestamp32", time.Unix(1, 0), "d6ff00000001"},
+		{"timestamp64", time.Unix(1, 1), "d7ff0000000400000001"},
+		{"timestamp96", time.Unix(-1, 0), "c70cff00000000ffffffffffffffff"},
+		{"uuid", uuid.MustParse("01234567-89ab-cdef-0123-456789abcdef"), "d92430313233343536372d383961622d636465662d303132332d343536373839616263646566"},
+		{"json marshaler", types.Duration(time.Second), "a23173"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := msgpack.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(b); got != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		{"int", "d0df", int64(-33)},
+		{"uint64", "cfffffffffffffffff", uint64(math.MaxUint64)},
+		{"float32", "ca3fc00000", 1.5},
+		{"str8", "d90161", "a"},
+		{"array16", "dc00020102", []interface{}{int64(1), int64(2)}},
+		{"map with int key", "810102", map[string]interface{}{"1": int64(2)}},
+		{"timestamp64", "d7ff0000000400000001", time.Unix(1, 1).UTC()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := hex.DecodeString(tt.data)
+			var got interface{}
+			if err := msgpack.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_invalid(t *testing.T) {
+	for _, data := range []string{"", "c1", "a2", "dc0002", "dbffffffff", "c3c3", "d4010000"} {
+		b, _ := hex.DecodeString(data)
+		var v interface{}
+		if err := msgpack.Unmarshal(b, &v); err == nil {
+			t.Errorf("Unmarshal(%s) error = nil", data)
+		}
+	}
+	var n int8
+	if err := msgpack.Unmarshal([]byte{0xcd, 1, 0}, &n); err == nil {
+		t.Error("Unmarshal() of overflow error = nil")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dt := time.Date(2018, 10, 16, 9, 58, 3, 487508407, time.UTC)
+	req := &sedoc.Request{
+		ID:        "42",
+		Datetime:  dt,
+		Session:   "01234567-89ab-cdef-0123-456789abcdef",
+		Command:   "user.list",
+		Arguments: sedoc.InterfaceMap{"count": int64(10), "raw": []byte{1, 2}, "at": dt},
+		Where:     []sedoc.InterfaceMap{{"login": "foo", "id[in]": []interface{}{int64(1), int64(2)}}},
+	}
+	b, err := msgpack.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &sedoc.Request{}
+	if err = msgpack.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, req) {
+		t.Errorf("request = %+v, want %+v", got, req)
+	}
+
+	total := 5
+	resp := &sedoc.Response{
+		Command: "user.list",
+		Result:  []interface{}{map[string]interface{}{"id": int64(1)}},
+		Page:    &sedoc.PageInfo{Count: 1, Total: &total},
+		Error: &sedoc.Error{Code: sedoc.ErrRateLimited, Description: "rate limit exceeded", RetryAfter: types.Duration(time.Second),
+			Details: []sedoc.ErrorDetail{{Field: "args.count", Reason: "invalid type"}}},
+	}
+	if b, err = msgpack.Marshal(resp); err != nil {
+		t.Fatal(err)
+	}
+	gotResp := &sedoc.Response{}
+	if err = msgpack.Unmarshal(b, gotResp); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotResp, resp) {
+		t.Errorf("response = %+v, want %+v", gotResp, resp)
+	}
+}
@@ -1,8 +1,13 @@
 package sedoc
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,6 +17,8 @@ import (
 // ArgumentParser is parser for Argument
 type ArgumentParser func(v interface{}, list bool) (r interface{}, err error)
 
+var argumentParsersMu sync.RWMutex
+
 var argumentParsers = map[ArgumentType]ArgumentParser{
 	ArgumentTypeBoolean:  parseBoolean,
 	ArgumentTypeDuration: parseDuration,
@@ -20,16 +27,44 @@ var argumentParsers = map[ArgumentType]ArgumentParser{
 	ArgumentTypeInteger:  parseInteger,
 	ArgumentTypeString:   parseString,
 	ArgumentTypeTime:     parseTime,
+	ArgumentTypeDate:     parseDate,
+	ArgumentTypeDecimal:  parseDecimal,
+	ArgumentTypeEmail:    parseEmail,
+	ArgumentTypeURL:      parseURL,
+	ArgumentTypeIP:       parseIP,
+	ArgumentTypeCIDR:     parseCIDR,
+	ArgumentTypeBytes:    parseBytes,
 }
 
-// SetArgumentParser func
+// SetArgumentParser set global parser for ArgumentType.
+//
+// Deprecated: use API RegisterArgumentType which is scoped per API and documented in help
 func SetArgumentParser(t ArgumentType, p ArgumentParser) {
+	argumentParsersMu.Lock()
+	defer argumentParsersMu.Unlock()
 	argumentParsers[t] = p
 }
 
+// globalParser return ArgumentParser which calls global parser of t at parse
+// time, so builtin DefaultArgumentTypes follow SetArgumentParser overrides
+func globalParser(t ArgumentType) ArgumentParser {
+	return func(v interface{}, list bool) (interface{}, error) {
+		argumentParsersMu.RLock()
+		p := argumentParsers[t]
+		argumentParsersMu.RUnlock()
+		if p == nil {
+			return nil, fmt.Errorf("nil parser: %s (%v)", t, v)
+		}
+		return p(v, list)
+	}
+}
+
 // Parse func
 func (t ArgumentType) Parse(v interface{}, list bool) (r interface{}, err error) {
-	if p, ok := argumentParsers[t]; !ok {
+	argumentParsersMu.RLock()
+	p, ok := argumentParsers[t]
+	argumentParsersMu.RUnlock()
+	if !ok {
 		err = fmt.Errorf("incompatible type: %s (%v)", t, v)
 	} else if p == nil {
 		err = fmt.Errorf("nil parser: %s (%v)", t, v)
@@ -233,11 +268,15 @@ func parseInteger(v interface{}, list bool) (r interface{}, err error) {
 		}
 	case int:
 		r = v
+	case int64:
+		r = int(v)
+	case uint64:
+		r = int(v)
 	case float64:
 		r = int(v)
 	case string:
 		var i int64
-		i, err = strconv.ParseInt(v, 10, 8)
+		i, err = strconv.ParseInt(v, 10, 64)
 		r = int(i)
 	default:
 		err = fmt.Errorf("incompatible integer type: %T (%v)", v, v)
@@ -285,6 +324,10 @@ func parseFloat(v interface{}, list bool) (r interface{}, err error) {
 		}
 	case int:
 		r = float64(v)
+	case int64:
+		r = float64(v)
+	case uint64:
+		r = float64(v)
 	case float64:
 		r = v
 	case string:
@@ -342,3 +385,175 @@ func parseString(v interface{}, list bool) (r interface{}, err error) {
 	}
 	return
 }
+
+// parseList parse v as list of T by parse func for single item
+func parseList[T any](v interface{}, name string, parse func(interface{}, bool) (interface{}, error)) (r interface{}, err error) {
+	if v == nil {
+		err = fmt.Errorf("list can not be nil")
+		return
+	}
+	switch v := v.(type) {
+	case []T:
+		r, err = parseItems[T](v, parse)
+	case []interface{}:
+		r, err = parseItems[T](v, parse)
+	case []string:
+		r, err = parseItems[T](v, parse)
+	default:
+		err = fmt.Errorf("incompatible %s list type: %T (%v)", name, v, v)
+	}
+	return
+}
+
+// parseItems parse each item of v by parse func for single item
+func parseItems[T any, S any](v []S, parse func(interface{}, bool) (interface{}, error)) (r []T, err error) {
+	r = make([]T, len(v))
+	for idx := range v {
+		var item interface{}
+		if item, err = parse(v[idx], false); err != nil {
+			return nil, err
+		}
+		r[idx] = item.(T)
+	}
+	return
+}
+
+func parseDate(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.Date](v, "date", parseDate)
+	}
+	switch v := v.(type) {
+	case types.Date:
+		r = v
+	case time.Time:
+		r = types.DateOf(v)
+	case string:
+		r, err = types.ParseDate(v)
+	default:
+		err = fmt.Errorf("incompatible date type: %T (%v)", v, v)
+		r = types.Date{}
+	}
+	return
+}
+
+func parseDecimal(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.Decimal](v, "decimal", parseDecimal)
+	}
+	switch v := v.(type) {
+	case types.Decimal:
+		r = v
+	case int:
+		r = types.NewDecimal(int64(v), 0)
+	case int64:
+		r = types.NewDecimal(v, 0)
+	case float64:
+		r, err = types.ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case json.Number:
+		r, err = types.ParseDecimal(v.String())
+	case string:
+		r, err = types.ParseDecimal(v)
+	default:
+		err = fmt.Errorf("incompatible decimal type: %T (%v)", v, v)
+		r = types.Decimal{}
+	}
+	return
+}
+
+func parseEmail(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.Email](v, "email", parseEmail)
+	}
+	switch v := v.(type) {
+	case types.Email:
+		r, err = types.ParseEmail(string(v))
+	case string:
+		r, err = types.ParseEmail(v)
+	default:
+		err = fmt.Errorf("incompatible email type: %T (%v)", v, v)
+		r = types.Email("")
+	}
+	return
+}
+
+func parseURL(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.URL](v, "url", parseURL)
+	}
+	switch v := v.(type) {
+	case types.URL:
+		r = v
+	case *url.URL:
+		r, err = types.ParseURL(v.String())
+	case url.URL:
+		r, err = types.ParseURL(v.String())
+	case string:
+		r, err = types.ParseURL(v)
+	default:
+		err = fmt.Errorf("incompatible url type: %T (%v)", v, v)
+		r = types.URL{}
+	}
+	return
+}
+
+func parseIP(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.IP](v, "ip", parseIP)
+	}
+	switch v := v.(type) {
+	case types.IP:
+		r = v
+	case netip.Addr:
+		r = types.IP{Addr: v}
+	case net.IP:
+		addr, ok := netip.AddrFromSlice(v)
+		if !ok {
+			err = fmt.Errorf("invalid ip: %v", v)
+		}
+		r = types.IP{Addr: addr.Unmap()}
+	case string:
+		r, err = types.ParseIP(v)
+	default:
+		err = fmt.Errorf("incompatible ip type: %T (%v)", v, v)
+		r = types.IP{}
+	}
+	return
+}
+
+func parseCIDR(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.CIDR](v, "cidr", parseCIDR)
+	}
+	switch v := v.(type) {
+	case types.CIDR:
+		r = v
+	case netip.Prefix:
+		r = types.CIDR{Prefix: v}
+	case *net.IPNet:
+		r, err = types.ParseCIDR(v.String())
+	case string:
+		r, err = types.ParseCIDR(v)
+	default:
+		err = fmt.Errorf("incompatible cidr type: %T (%v)", v, v)
+		r = types.CIDR{}
+	}
+	return
+}
+
+func parseBytes(v interface{}, list bool) (r interface{}, err error) {
+	if list {
+		return parseList[types.Bytes](v, "base64", parseBytes)
+	}
+	switch v := v.(type) {
+	case types.Bytes:
+		r = v
+	case []byte:
+		r = types.Bytes(v)
+	case string:
+		r, err = types.ParseBytes(v)
+	default:
+		err = fmt.Errorf("incompatible base64 type: %T (%v)", v, v)
+		r = types.Bytes(nil)
+	}
+	return
+}
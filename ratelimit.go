@@ -0,0 +1,184 @@
+package sedoc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+// Rate is token bucket limit: Requests per Per duration with Burst
+// (Requests if zero) requests allowed at once
+type Rate struct {
+	XMLName  xml.Name       `json:"-" xml:"rate_limit" yaml:"-"`
+	Requests int            `json:"requests" xml:"requests,attr" yaml:"requests"`
+	Per      types.Duration `json:"per" xml:"per,attr" yaml:"per"`
+	Burst    int            `json:"burst,omitempty" xml:"burst,attr,omitempty" yaml:"burst,omitempty"`
+}
+
+// String method
+func (r Rate) String() string {
+	return fmt.Sprintf("%d per %s", r.Requests, r.Per)
+}
+
+// Validate return error if rate does not allow any request
+func (r Rate) Validate() error {
+	if r.Requests <= 0 || r.Per <= 0 || r.Burst < 0 {
+		return fmt.Errorf("invalid rate limit: %s", r)
+	}
+	return nil
+}
+
+// tokens return refill rate (tokens per second) and bucket capacity
+func (r Rate) tokens() (perSecond, capacity float64) {
+	capacity = float64(r.Burst)
+	if r.Burst <= 0 {
+		capacity = float64(r.Requests)
+	}
+	return float64(r.Requests) / time.Duration(r.Per).Seconds(), capacity
+}
+
+// RateLimitBucket is token bucket Key limited by Rate
+type RateLimitBucket struct {
+	Key  string
+	Rate Rate
+}
+
+// RateLimitStore is storage of token buckets. It must be safe for concurrent use
+type RateLimitStore interface {
+	// Take token from each of buckets if all of them have one. Otherwise no
+	// token is taken, rejected is index of first bucket without token and
+	// retryAfter is duration after which its token will be available
+	Take(buckets []RateLimitBucket, now time.Time) (ok bool, rejected int, retryAfter time.Duration, err error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// NewMemoryRateLimitStore return in-memory RateLimitStore.
+// Refilled buckets are removed once a minute
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: map[string]*bucket{}}
+}
+
+func (s *memoryRateLimitStore) Take(buckets []RateLimitBucket, now time.Time) (bool, int, time.Duration, error) {
+	for _, rb := range buckets {
+		if err := rb.Rate.Validate(); err != nil {
+			return false, 0, 0, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.swept) > time.Minute {
+		for k, b := range s.buckets {
+			if !b.full.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+	refilled := make([]*bucket, len(buckets))
+	for idx, rb := range buckets {
+		perSecond, capacity := rb.Rate.tokens()
+		b := s.buckets[rb.Key]
+		if b == nil {
+			b = &bucket{tokens: capacity, last: now}
+			s.buckets[rb.Key] = b
+		}
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+		b.last = now
+		if b.tokens < 1 {
+			return false, idx, time.Duration((1 - b.tokens) / perSecond * float64(time.Second)), nil
+		}
+		refilled[idx] = b
+	}
+	for idx, b := range refilled {
+		perSecond, capacity := buckets[idx].Rate.tokens()
+		b.tokens--
+		b.full = now.Add(time.Duration((capacity - b.tokens) / perSecond * float64(time.Second)))
+	}
+	return true, 0, 0, nil
+}
+
+// RateLimitConfig is RateLimit middleware config
+type RateLimitConfig struct {
+	// Store of token buckets, in-memory store by default
+	Store RateLimitStore
+	// Global limit for all requests
+	Global *Rate
+	// Identity limit for all requests of single identity
+	Identity *Rate
+	// Identifier return identity of request, Request Session by default.
+	// Command RateLimit is applied per identity too
+	Identifier func(Context) string
+	// Skipper return true for requests which are not limited
+	Skipper func(Context) bool
+}
+
+// RateLimit return middleware which limits requests by token buckets:
+// Command RateLimit (per identity), config Identity and config Global.
+// Tokens are taken only if all limits allow request, so request rejected by
+// one limit does not take tokens of others.
+// Rejected requests fail with ErrRateLimited carrying RetryAfter.
+// RateLimit panics if config Global or Identity rate is invalid
+func RateLimit(config RateLimitConfig) MiddlewareFunc {
+	for _, rate := range []*Rate{config.Global, config.Identity} {
+		if rate != nil {
+			if err := rate.Validate(); err != nil {
+				panic(err)
+			}
+		}
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+	if config.Identifier == nil {
+		config.Identifier = func(c Context) string { return c.Request().Session }
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			id := config.Identifier(c)
+			buckets := make([]RateLimitBucket, 0, 3)
+			for _, limit := range []struct {
+				key  string
+				rate *Rate
+			}{
+				{"command\x00" + c.Command().Name + "\x00" + id, c.Command().RateLimit},
+				{"identity\x00" + id, config.Identity},
+				{"global", config.Global},
+			} {
+				if limit.rate != nil {
+					buckets = append(buckets, RateLimitBucket{Key: limit.key, Rate: *limit.rate})
+				}
+			}
+			if len(buckets) == 0 {
+				return next(c)
+			}
+			ok, rejected, retryAfter, err := config.Store.Take(buckets, time.Now())
+			if err != nil {
+				return c.ErrorInternal(ErrUnknown, err)
+			}
+			if !ok {
+				serr := c.Error(ErrRateLimited, buckets[rejected].Rate)
+				serr.RetryAfter = types.Duration(retryAfter)
+				return serr
+			}
+			return next(c)
+		}
+	}
+}
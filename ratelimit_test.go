@@ -0,0 +1,129 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsemikov/go-sedoc/types"
+)
+
+func TestMemoryRateLimitStore_Take(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	rate := Rate{Requests: 2, Per: types.Duration(time.Second)}
+	now := time.Now()
+	steps := []struct {
+		after     time.Duration
+		want      bool
+		wantRetry time.Duration
+	}{
+		{0, true, 0},
+		{0, true, 0},
+		{0, false, 500 * time.Millisecond},
+		{250 * time.Millisecond, false, 250 * time.Millisecond},
+		{500 * time.Millisecond, true, 0},
+		{500 * time.Millisecond, false, 500 * time.Millisecond},
+		{5 * time.Second, true, 0},
+		{5 * time.Second, true, 0},
+	}
+	for idx, step := range steps {
+		ok, _, retry, err := s.Take([]RateLimitBucket{{"key", rate}}, now.Add(step.after))
+		if diff := retry - step.wantRetry; err != nil || ok != step.want || diff > time.Millisecond || diff < -time.Millisecond {
+			t.Errorf("step %d: Take() = %v, %v, %v, want %v, %v", idx, ok, retry, err, step.want, step.wantRetry)
+		}
+	}
+	if _, _, _, err := s.Take([]RateLimitBucket{{"invalid", Rate{Requests: 1}}}, now); err == nil {
+		t.Error("Take() with zero Per succeeded")
+	}
+	// rejected by second bucket, first one keeps its token
+	wide := Rate{Requests: 1, Per: types.Duration(time.Hour)}
+	s.Take([]RateLimitBucket{{"wide", wide}}, now)
+	for idx := 0; idx < 2; idx++ {
+		ok, rejected, _, err := s.Take([]RateLimitBucket{{"narrow", wide}, {"wide", wide}}, now)
+		if ok || rejected != 1 || err != nil {
+			t.Errorf("Take() = %v, %d, %v, want rejected 1", ok, rejected, err)
+		}
+	}
+	if ok, _, _, _ := s.Take([]RateLimitBucket{{"narrow", wide}}, now); !ok {
+		t.Error("Take() of bucket charged by rejected request failed")
+	}
+}
+
+func TestRate_Validate(t *testing.T) {
+	for _, rate := range []Rate{{Requests: 0, Per: 1}, {Requests: 1, Per: 0}, {Requests: 1, Per: 1, Burst: -1}} {
+		if rate.Validate() == nil {
+			t.Errorf("Validate(%+v) = nil", rate)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimit(Global: %+v) does not panic", rate)
+				}
+			}()
+			RateLimit(RateLimitConfig{Global: &rate})
+		}()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("AddCommand(RateLimit: %+v) does not panic", rate)
+				}
+			}()
+			New().AddCommand(Command{Name: "foo", RateLimit: &rate, Handler: func(Context) error { return nil }})
+		}()
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	a := New()
+	a.Use(RateLimit(RateLimitConfig{
+		Global:   &Rate{Requests: 4, Per: types.Duration(time.Hour)},
+		Identity: &Rate{Requests: 2, Per: types.Duration(time.Hour)},
+	}))
+	a.AddCommand(Command{
+		Name:      "report.build",
+		RateLimit: &Rate{Requests: 1, Per: types.Duration(time.Hour)},
+		Handler:   func(c Context) error { return nil },
+	})
+	sessions := []string{"01234567-89ab-cdef-0123-456789abcdef", "11234567-89ab-cdef-0123-456789abcdef"}
+	tests := []struct {
+		name    string
+		req     *Request
+		limited bool
+	}{
+		{"command", &Request{Command: "report.build", Session: sessions[0]}, false},
+		{"command limit", &Request{Command: "report.build", Session: sessions[0]}, true},
+		{"command another session", &Request{Command: "report.build", Session: sessions[1]}, false},
+		{"help", &Request{Command: "help", Session: sessions[0]}, false},
+		{"identity limit", &Request{Command: "help", Session: sessions[0]}, true},
+		{"help another session", &Request{Command: "help", Session: sessions[1]}, false},
+		{"global limit", &Request{Command: "help"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := a.Execute(tt.req)
+			limited := resp.Error != nil && resp.Error.Code == ErrRateLimited
+			if limited != tt.limited {
+				t.Fatalf("Execute() error = %v, want limited %v", resp.Error, tt.limited)
+			}
+			if limited && resp.Error.RetryAfter <= 0 {
+				t.Errorf("Error RetryAfter = %v", resp.Error.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestRate_help(t *testing.T) {
+	cmd := Command{Name: "foo", RateLimit: &Rate{Requests: 10, Per: types.Duration(time.Minute), Burst: 2}}
+	b, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"rate_limit":{"requests":10,"per":"1m0s","burst":2}`; !strings.Contains(string(b), want) {
+		t.Errorf("json = %s, want %s", b, want)
+	}
+	e := Error{Code: ErrRateLimited, RetryAfter: types.Duration(time.Second)}
+	if b, _ = json.Marshal(e); !strings.Contains(string(b), `"retry_after":"1s"`) {
+		t.Errorf("json = %s", b)
+	}
+}
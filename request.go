@@ -16,6 +16,8 @@ type Request struct {
 	Arguments InterfaceMap   `json:"args,omitempty" xml:"args,omitempty" yaml:"args,omitempty"`
 	Where     []InterfaceMap `json:"where,omitempty" xml:"where,omitempty" yaml:"where,omitempty"`
 	Set       InterfaceMap   `json:"set,omitempty" xml:"set,omitempty" yaml:"set,omitempty"`
+	Trace     string         `json:"trace,omitempty" xml:"trace,attr,omitempty" yaml:"trace,omitempty"`
+	Locale    string         `json:"locale,omitempty" xml:"locale,attr,omitempty" yaml:"locale,omitempty"`
 }
 
 // String format Request as string
@@ -32,3 +34,57 @@ func NewRequest() *Request {
 		Set:       make(InterfaceMap),
 	}
 }
+
+// Clone return deep copy of Request: its Arguments, Where and Set maps and
+// nested maps and slices of values are copied
+func (r *Request) Clone() *Request {
+	cp := *r
+	cp.Arguments = cloneMap(r.Arguments)
+	cp.Set = cloneMap(r.Set)
+	if r.Where != nil {
+		cp.Where = make([]InterfaceMap, len(r.Where))
+		for idx := range r.Where {
+			cp.Where[idx] = cloneMap(r.Where[idx])
+		}
+	}
+	return &cp
+}
+
+func cloneMap(m InterfaceMap) InterfaceMap {
+	if m == nil {
+		return nil
+	}
+	return cloneValue(m).(InterfaceMap)
+}
+
+// cloneValue return deep copy of generic maps and slices in v, other values
+// are returned as is
+func cloneValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case InterfaceMap:
+		res := make(InterfaceMap, len(val))
+		for key, item := range val {
+			res[key] = cloneValue(item)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			res[key] = cloneValue(item)
+		}
+		return res
+	case []InterfaceMap:
+		res := make([]InterfaceMap, len(val))
+		for idx := range val {
+			res[idx] = cloneValue(val[idx]).(InterfaceMap)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for idx := range val {
+			res[idx] = cloneValue(val[idx])
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,188 @@
+package sedoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// scalarResult is name of single Argument which describes non-object Result.
+// It is distinct from names of object fields (json tag or Go field name)
+const scalarResult = ""
+
+// ResultOf describe fields of struct v (by json tags) as Arguments.
+// Nested structs are described by ArgumentTypeObject Argument with Fields.
+// Non-struct v is described by single Argument without Name
+func ResultOf(v interface{}) Arguments {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || goArgumentTypes[t] != "" {
+		return Arguments{resultArgument(scalarResult, t, nil)}
+	}
+	return resultFields(t, map[reflect.Type]bool{})
+}
+
+func resultFields(t reflect.Type, seen map[reflect.Type]bool) (args Arguments) {
+	seen[t] = true
+	defer delete(seen, t)
+	for idx := 0; idx < t.NumField(); idx++ {
+		sf := t.Field(idx)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && len(name) == 0 && sf.Type.Kind() == reflect.Struct {
+			args = append(args, resultFields(sf.Type, seen)...)
+			continue
+		}
+		if name == "-" || sf.PkgPath != "" {
+			continue
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+		arg := resultArgument(name, sf.Type, seen)
+		arg.Description = sf.Tag.Get(tagDescription)
+		args = append(args, arg)
+	}
+	return
+}
+
+func resultArgument(name string, t reflect.Type, seen map[reflect.Type]bool) (arg Argument) {
+	arg.Name = name
+	if t.Kind() == reflect.Ptr {
+		arg.Nullable = true
+		t = t.Elem()
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && goArgumentTypes[t] == "" {
+		arg.Multiple = true
+		t = t.Elem()
+	}
+	var err error
+	if arg.Type, err = argumentTypeOf(t); err == nil {
+		return
+	}
+	arg.Type = ArgumentTypeObject
+	if t.Kind() == reflect.Struct && seen != nil && !seen[t] {
+		arg.Fields = resultFields(t, seen)
+	}
+	return
+}
+
+// CheckResult is middleware which checks that Response Result conforms to
+// Command Result schema. It is intended for debug mode: on mismatch handler
+// error is replaced by ErrInvalidResult. Result of async Command Job is not checked
+func CheckResult(next HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		if err := next(c); err != nil || c.Command().Kind == CommandKindAsync {
+			return err
+		}
+		if err := checkResult(c.Response().Result, c.Command().Result); err != nil {
+			serr := DefaultErrors.Get(ErrInvalidResult)
+			serr.Description = fmt.Sprintf("%s: %v", serr.Description, err)
+			serr.Internal = err
+			c.Response().Result = nil
+			return &serr
+		}
+		return nil
+	}
+}
+
+func checkResult(result interface{}, schema Arguments) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	// normalize result to the form it has on the wire
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var v interface{}
+	if err = json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if len(schema) == 1 && schema[0].Name == scalarResult {
+		return checkResultValue(schema[0], v, "result")
+	}
+	if v == nil {
+		return nil
+	}
+	return checkResultObject(schema, v, "")
+}
+
+func checkResultObject(fields Arguments, v interface{}, path string) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: object expected, got %T", strings.TrimSuffix(path, "."), v)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	for _, arg := range fields {
+		if _, ok := m[arg.Name]; !ok && arg.Required {
+			return fmt.Errorf("%s%s: required field missing", path, arg.Name)
+		}
+	}
+	for name, val := range m {
+		arg, err := fields.Get(name)
+		if err != nil {
+			return fmt.Errorf("%s%s: undeclared field", path, name)
+		}
+		if err = checkResultValue(arg, val, path+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkResultValue(arg Argument, v interface{}, path string) error {
+	if v == nil {
+		if arg.Nullable || arg.Multiple || !arg.Required {
+			return nil
+		}
+		return fmt.Errorf("%s: null value", path)
+	}
+	if arg.Multiple {
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: array expected, got %T", path, v)
+		}
+		arg.Multiple = false
+		arg.Required = true
+		for idx, item := range arr {
+			if err := checkResultValue(arg, item, fmt.Sprintf("%s[%d]", path, idx)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	ok := true
+	switch arg.Type {
+	case ArgumentTypeBoolean:
+		_, ok = v.(bool)
+	case ArgumentTypeInteger:
+		var f float64
+		f, ok = v.(float64)
+		ok = ok && f == math.Trunc(f)
+	case ArgumentTypeFloat:
+		_, ok = v.(float64)
+	case ArgumentTypeString:
+		_, ok = v.(string)
+	case ArgumentTypeArray:
+		_, ok = v.([]interface{})
+	case ArgumentTypeObject:
+		return checkResultObject(arg.Fields, v, path+".")
+	default:
+		if _, err := arg.Type.Parse(v, false); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%s: %s expected, got %T", path, arg.Type, v)
+	}
+	return nil
+}
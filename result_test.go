@@ -0,0 +1,111 @@
+package sedoc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResultOf(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want Arguments
+	}{
+		{"nil", nil, nil},
+		{"string", "", Arguments{{Type: ArgumentTypeString}}},
+		{"slice", []int{}, Arguments{{Type: ArgumentTypeInteger, Multiple: true}}},
+		{"struct", &handleOut{}, Arguments{
+			{Name: "id", Type: ArgumentTypeInteger},
+			{Name: "name", Type: ArgumentTypeString, Description: "User name"},
+			{Name: "tags", Type: ArgumentTypeString, Multiple: true},
+			{Name: "Parent", Type: ArgumentTypeObject, Nullable: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResultOf(tt.v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResultOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type resultItem struct {
+	Title string `json:"title"`
+}
+
+type resultList struct {
+	Total int          `json:"total"`
+	Items []resultItem `json:"items"`
+}
+
+func TestResultOf_nested(t *testing.T) {
+	want := Arguments{
+		{Name: "total", Type: ArgumentTypeInteger},
+		{Name: "items", Type: ArgumentTypeObject, Multiple: true, Fields: Arguments{
+			{Name: "title", Type: ArgumentTypeString},
+		}},
+	}
+	if got := ResultOf(resultList{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ResultOf() = %v, want %v", got, want)
+	}
+}
+
+type resultWrapped struct {
+	Result int `json:"result"`
+}
+
+func Test_checkResult(t *testing.T) {
+	list := ResultOf(resultList{})
+	wrapped := ResultOf(resultWrapped{})
+	tests := []struct {
+		name    string
+		result  interface{}
+		schema  Arguments
+		wantErr bool
+	}{
+		{"no schema", 5, nil, false},
+		{"nil", nil, list, false},
+		{"struct", resultList{Total: 1, Items: []resultItem{{"a"}}}, list, false},
+		{"map", map[string]interface{}{"total": 1, "items": []interface{}{map[string]interface{}{"title": "a"}}}, list, false},
+		{"not integer", map[string]interface{}{"total": 1.5}, list, true},
+		{"undeclared", map[string]interface{}{"count": 1}, list, true},
+		{"nested undeclared", map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "a"}}}, list, true},
+		{"not array", map[string]interface{}{"items": "a"}, list, true},
+		{"required", map[string]interface{}{}, Arguments{{Name: "id", Type: ArgumentTypeInteger, Required: true}}, true},
+		{"string", "foo", ResultOf(""), false},
+		{"string mismatch", 5, ResultOf(""), true},
+		{"uuid", "01234567-89ab-cdef-0123-456789abcdef", Arguments{{Type: ArgumentTypeUUID}}, false},
+		{"uuid mismatch", "foo", Arguments{{Type: ArgumentTypeUUID}}, true},
+		{"wrapped", resultWrapped{Result: 1}, wrapped, false},
+		{"wrapped scalar", 1, wrapped, true},
+		{"scalar wrapped", resultWrapped{Result: 1}, ResultOf(0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkResult(tt.result, tt.schema); (err != nil) != tt.wantErr {
+				t.Errorf("checkResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckResult(t *testing.T) {
+	a := New()
+	a.Use(CheckResult)
+	a.AddCommand(Command{
+		Name:   "count",
+		Result: Arguments{{Type: ArgumentTypeInteger}},
+		Handler: func(c Context) error {
+			c.Response().Result = c.Request().Arguments["v"]
+			return nil
+		},
+		Arguments: Arguments{{Name: "v", Type: ArgumentTypeString}},
+	})
+	if resp := a.Execute(&Request{Command: "count", Arguments: InterfaceMap{"v": "foo"}}); resp.Error == nil || resp.Error.Code != ErrInvalidResult {
+		t.Errorf("Execute() = %v, want error %d", resp, ErrInvalidResult)
+	}
+	if resp := a.Execute(&Request{Command: "help"}); resp.Error != nil {
+		t.Errorf("Execute(help) error = %v", resp.Error)
+	}
+}
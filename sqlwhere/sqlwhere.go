@@ -0,0 +1,213 @@
+/*
+Package sqlwhere converts validated sedoc request Where into parameterized
+SQL WHERE clause fragment.
+
+Only fields declared in Command Where Arguments (and only their allowed
+Operators) may be used, column names are taken from Argument Column (or
+Name) and quoted by Dialect, so identifiers never come from request.
+*/
+package sqlwhere
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+)
+
+// Dialect describes SQL dialect specifics
+type Dialect interface {
+	// Placeholder return bind parameter placeholder with 1-based index n
+	Placeholder(n int) string
+	// Quote return quoted identifier
+	Quote(ident string) string
+}
+
+type postgres struct{}
+
+func (postgres) Placeholder(n int) string  { return "$" + strconv.Itoa(n) }
+func (postgres) Quote(ident string) string { return `"` + ident + `"` }
+
+type mysql struct{}
+
+func (mysql) Placeholder(n int) string  { return "?" }
+func (mysql) Quote(ident string) string { return "`" + ident + "`" }
+
+type sqlite struct{}
+
+func (sqlite) Placeholder(n int) string  { return "?" }
+func (sqlite) Quote(ident string) string { return `"` + ident + `"` }
+
+var (
+	// PostgreSQL dialect ($1, $2 placeholders and "ident" quoting)
+	PostgreSQL Dialect = postgres{}
+	// MySQL dialect (? placeholders and `ident` quoting)
+	MySQL Dialect = mysql{}
+	// SQLite dialect (? placeholders and "ident" quoting)
+	SQLite Dialect = sqlite{}
+)
+
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Builder builds WHERE clause fragment
+type Builder struct {
+	// Dialect of SQL
+	Dialect Dialect
+	// Arguments is whitelist of fields, usually Command Where
+	Arguments sedoc.Arguments
+	// Offset is count of placeholders already used in query before fragment
+	Offset int
+}
+
+// Build return WHERE clause fragment (without WHERE keyword) and its
+// parameters for Filter. Empty Filter is converted to "1=1"
+func Build(d Dialect, args sedoc.Arguments, filter sedoc.Filter) (string, []interface{}, error) {
+	return Builder{Dialect: d, Arguments: args}.Build(filter)
+}
+
+// BuildWhere parse request Where and Build it
+func BuildWhere(d Dialect, args sedoc.Arguments, where []sedoc.InterfaceMap) (string, []interface{}, error) {
+	filter, err := sedoc.ParseFilter(where)
+	if err != nil {
+		return "", nil, err
+	}
+	return Build(d, args, filter)
+}
+
+// Build return WHERE clause fragment (without WHERE keyword) and its parameters
+func (b Builder) Build(filter sedoc.Filter) (string, []interface{}, error) {
+	if b.Dialect == nil {
+		return "", nil, fmt.Errorf("sqlwhere: nil dialect")
+	}
+	if len(filter) == 0 {
+		return "1=1", nil, nil
+	}
+	params := []interface{}{}
+	ors := make([]string, 0, len(filter))
+	for _, conds := range filter {
+		if len(conds) == 0 {
+			ors = append(ors, "1=1")
+			continue
+		}
+		ands := make([]string, 0, len(conds))
+		for _, cond := range conds {
+			s, p, err := b.condition(cond, b.Offset+len(params))
+			if err != nil {
+				return "", nil, err
+			}
+			ands = append(ands, s)
+			params = append(params, p...)
+		}
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	if len(ors) == 1 {
+		return ors[0], params, nil
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", params, nil
+}
+
+func (b Builder) column(cond sedoc.Condition) (string, error) {
+	arg, err := b.Arguments.Get(cond.Field)
+	if err != nil {
+		return "", fmt.Errorf("sqlwhere: undeclared field: %s", cond.Field)
+	}
+	if !arg.AllowedOperators().Contains(cond.Operator) {
+		return "", fmt.Errorf("sqlwhere: operator %s is not allowed for field %s", cond.Operator, cond.Field)
+	}
+	column := arg.Column
+	if len(column) == 0 {
+		column = arg.Name
+	}
+	if !identRegexp.MatchString(column) {
+		return "", fmt.Errorf("sqlwhere: invalid column name: %s", column)
+	}
+	parts := strings.Split(column, ".")
+	for idx := range parts {
+		parts[idx] = b.Dialect.Quote(parts[idx])
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func (b Builder) condition(cond sedoc.Condition, used int) (string, []interface{}, error) {
+	col, err := b.column(cond)
+	if err != nil {
+		return "", nil, err
+	}
+	list, isList := values(cond.Value)
+	ph := func(n int) string { return b.Dialect.Placeholder(used + n) }
+	switch cond.Operator {
+	case sedoc.OperatorEq, sedoc.OperatorNe:
+		not := cond.Operator == sedoc.OperatorNe
+		switch {
+		case cond.Value == nil && not:
+			return col + " IS NOT NULL", nil, nil
+		case cond.Value == nil:
+			return col + " IS NULL", nil, nil
+		case isList:
+			return b.in(col, list, not, used)
+		case not:
+			return col + " <> " + ph(1), []interface{}{cond.Value}, nil
+		}
+		return col + " = " + ph(1), []interface{}{cond.Value}, nil
+	case sedoc.OperatorLt, sedoc.OperatorLe, sedoc.OperatorGt, sedoc.OperatorGe:
+		op := map[sedoc.Operator]string{sedoc.OperatorLt: " < ", sedoc.OperatorLe: " <= ", sedoc.OperatorGt: " > ", sedoc.OperatorGe: " >= "}[cond.Operator]
+		if cond.Value == nil || isList {
+			return "", nil, fmt.Errorf("sqlwhere: single value expected for %s[%s]", cond.Field, cond.Operator)
+		}
+		return col + op + ph(1), []interface{}{cond.Value}, nil
+	case sedoc.OperatorIn:
+		if !isList {
+			list = []interface{}{cond.Value}
+		}
+		return b.in(col, list, false, used)
+	case sedoc.OperatorLike:
+		return col + " LIKE " + ph(1), []interface{}{cond.Value}, nil
+	case sedoc.OperatorNull:
+		if isNull, ok := cond.Value.(bool); !ok {
+			return "", nil, fmt.Errorf("sqlwhere: boolean value expected for %s[%s]", cond.Field, cond.Operator)
+		} else if isNull {
+			return col + " IS NULL", nil, nil
+		}
+		return col + " IS NOT NULL", nil, nil
+	case sedoc.OperatorBetween:
+		if len(list) != 2 {
+			return "", nil, fmt.Errorf("sqlwhere: two values expected for %s[%s]", cond.Field, cond.Operator)
+		}
+		return col + " BETWEEN " + ph(1) + " AND " + ph(2), list, nil
+	}
+	return "", nil, fmt.Errorf("sqlwhere: unsupported operator: %s", cond.Operator)
+}
+
+func (b Builder) in(col string, list []interface{}, not bool, used int) (string, []interface{}, error) {
+	if len(list) == 0 {
+		if not {
+			return "1=1", nil, nil
+		}
+		return "1=0", nil, nil
+	}
+	phs := make([]string, len(list))
+	for idx := range list {
+		phs[idx] = b.Dialect.Placeholder(used + idx + 1)
+	}
+	op := " IN ("
+	if not {
+		op = " NOT IN ("
+	}
+	return col + op + strings.Join(phs, ", ") + ")", list, nil
+}
+
+// values return items of slice v (except []byte)
+func values(v interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	list := make([]interface{}, rv.Len())
+	for idx := range list {
+		list[idx] = rv.Index(idx).Interface()
+	}
+	return list, true
+}
@@ -0,0 +1,105 @@
+package sqlwhere_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+	"github.com/nsemikov/go-sedoc/sqlwhere"
+)
+
+var args = sedoc.Arguments{
+	{Name: "id", Type: sedoc.ArgumentTypeInteger, Multiple: true, Operators: sedoc.Operators{sedoc.OperatorEq, sedoc.OperatorNe, sedoc.OperatorIn}},
+	{Name: "name", Type: sedoc.ArgumentTypeString, Column: "u.full_name", Operators: sedoc.Operators{sedoc.OperatorEq, sedoc.OperatorLike}},
+	{Name: "email", Type: sedoc.ArgumentTypeEmail, Nullable: true, Operators: sedoc.Operators{sedoc.OperatorEq, sedoc.OperatorNull}},
+	{Name: "created", Type: sedoc.ArgumentTypeTime, Operators: sedoc.ComparisonOperators},
+	{Name: "evil", Type: sedoc.ArgumentTypeString, Column: "id; DROP TABLE users"},
+}
+
+func TestBuild(t *testing.T) {
+	t1 := time.Date(2018, 10, 16, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2019, 10, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		dialect    sqlwhere.Dialect
+		filter     sedoc.Filter
+		wantSQL    string
+		wantParams []interface{}
+		wantErr    bool
+	}{
+		{"empty", sqlwhere.PostgreSQL, nil, "1=1", nil, false},
+		{"eq", sqlwhere.PostgreSQL, sedoc.Filter{{{Field: "name", Operator: sedoc.OperatorEq, Value: "foo"}}},
+			`("u"."full_name" = $1)`, []interface{}{"foo"}, false},
+		{"eq multiple", sqlwhere.MySQL, sedoc.Filter{{{Field: "id", Operator: sedoc.OperatorEq, Value: []int{1, 2}}}},
+			"(`id` IN (?, ?))", []interface{}{1, 2}, false},
+		{"ne multiple", sqlwhere.SQLite, sedoc.Filter{{{Field: "id", Operator: sedoc.OperatorNe, Value: []int{1}}}},
+			`("id" NOT IN (?))`, []interface{}{1}, false},
+		{"in empty", sqlwhere.SQLite, sedoc.Filter{{{Field: "id", Operator: sedoc.OperatorIn, Value: []int{}}}},
+			`(1=0)`, []interface{}{}, false},
+		{"null", sqlwhere.PostgreSQL, sedoc.Filter{{{Field: "email", Operator: sedoc.OperatorNull, Value: false}}, {{Field: "email", Operator: sedoc.OperatorEq, Value: nil}}},
+			`(("email" IS NOT NULL) OR ("email" IS NULL))`, []interface{}{}, false},
+		{"and or", sqlwhere.PostgreSQL, sedoc.Filter{
+			{{Field: "created", Operator: sedoc.OperatorGe, Value: t1}, {Field: "name", Operator: sedoc.OperatorLike, Value: "fo%"}},
+			{{Field: "created", Operator: sedoc.OperatorBetween, Value: []time.Time{t1, t2}}, {Field: "id", Operator: sedoc.OperatorIn, Value: []int{3, 4}}},
+		}, `(("created" >= $1 AND "u"."full_name" LIKE $2) OR ("created" BETWEEN $3 AND $4 AND "id" IN ($5, $6)))`,
+			[]interface{}{t1, "fo%", t1, t2, 3, 4}, false},
+		{"undeclared", sqlwhere.PostgreSQL, sedoc.Filter{{{Field: "password", Operator: sedoc.OperatorEq, Value: "x"}}}, "", nil, true},
+		{"not allowed", sqlwhere.PostgreSQL, sedoc.Filter{{{Field: "name", Operator: sedoc.OperatorGt, Value: "x"}}}, "", nil, true},
+		{"invalid column", sqlwhere.PostgreSQL, sedoc.Filter{{{Field: "evil", Operator: sedoc.OperatorEq, Value: "x"}}}, "", nil, true},
+		{"between one", sqlwhere.PostgreSQL, sedoc.Filter{{{Field: "created", Operator: sedoc.OperatorBetween, Value: []time.Time{t1}}}}, "", nil, true},
+		{"nil dialect", nil, nil, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSQL, gotParams, err := sqlwhere.Build(tt.dialect, args, tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotSQL != tt.wantSQL {
+				t.Errorf("Build() sql = %v, want %v", gotSQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(gotParams, tt.wantParams) {
+				t.Errorf("Build() params = %v, want %v", gotParams, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestBuilder_Offset(t *testing.T) {
+	b := sqlwhere.Builder{Dialect: sqlwhere.PostgreSQL, Arguments: args, Offset: 2}
+	got, _, err := b.Build(sedoc.Filter{{{Field: "id", Operator: sedoc.OperatorIn, Value: []int{1, 2}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `("id" IN ($3, $4))`; got != want {
+		t.Errorf("Builder.Build() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildWhere(t *testing.T) {
+	a := sedoc.New()
+	var gotSQL string
+	var gotParams []interface{}
+	a.AddCommand(sedoc.Command{
+		Name:  "user.get",
+		Where: args,
+		Handler: func(c sedoc.Context) (err error) {
+			gotSQL, gotParams, err = sqlwhere.BuildWhere(sqlwhere.PostgreSQL, c.Command().Where, c.Request().Where)
+			return
+		},
+	})
+	resp := a.Execute(&sedoc.Request{
+		Command: "user.get",
+		Where:   []sedoc.InterfaceMap{{"id[in]": []interface{}{"1", "2"}, "email": map[string]interface{}{"null": true}}},
+	})
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	if want := `("email" IS NULL AND "id" IN ($1, $2))`; gotSQL != want {
+		t.Errorf("BuildWhere() sql = %v, want %v", gotSQL, want)
+	}
+	if want := []interface{}{1, 2}; !reflect.DeepEqual(gotParams, want) {
+		t.Errorf("BuildWhere() params = %v, want %v", gotParams, want)
+	}
+}
@@ -0,0 +1,307 @@
+package sedoc
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// StreamWriter writes Response incrementally: Begin is called once with
+// Response header (Result, Page, Meta and Error are not set yet), Item is
+// called for each emitted result item and End is called once with final
+// Response carrying Page, Meta and Error
+type StreamWriter interface {
+	Begin(resp *Response) error
+	Item(v interface{}) error
+	End(resp *Response) error
+}
+
+// EmitAll emit each value received from ch until ch is closed or Emit fails
+func EmitAll[T any](c Context, ch <-chan T) error {
+	for v := range ch {
+		if err := c.Emit(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmitSeq emit each value yielded by seq (compatible with iter.Seq) until Emit fails
+func EmitSeq[T any](c Context, seq func(yield func(T) bool)) (err error) {
+	seq(func(v T) bool {
+		err = c.Emit(v)
+		return err == nil
+	})
+	return
+}
+
+// ExecuteStream execute command from API writing result items into w as
+// they are emitted by handler (see Context Emit). Result set by handler is
+// written as its items. Returned error is first error of w
+func (api *API) ExecuteStream(request *Request, w StreamWriter) error {
+	return api.ExecuteStreamContext(stdcontext.Background(), request, w)
+}
+
+// ExecuteStreamContext is ExecuteStream with context of execution
+func (api *API) ExecuteStreamContext(ctx stdcontext.Context, request *Request, w StreamWriter) error {
+	c := &context{ctx: ctx, api: api, req: request, stream: w}
+	api.execute(c)
+	return c.streamErr
+}
+
+// Emit write result item into stream or append it to Response Result
+func (c *context) Emit(v interface{}) error {
+	if c.stream == nil {
+		arr, ok := c.Response().Result.([]interface{})
+		if !ok && c.Response().Result != nil {
+			return fmt.Errorf("sedoc: can not emit into Response Result of type %T", c.Response().Result)
+		}
+		c.Response().Result = append(arr, v)
+		return nil
+	}
+	if fields := c.Page().Fields; len(fields) > 0 {
+		var err error
+		if v, err = Project(v, fields); err != nil {
+			return err
+		}
+	}
+	return c.streamItem(v)
+}
+
+func (c *context) beginStream() error {
+	if !c.streamBegun && c.streamErr == nil {
+		c.streamBegun = true
+		fillResponseMissingDataFromRequest(c.Request(), c.Response())
+		c.streamErr = c.stream.Begin(streamHead(c.Response()))
+	}
+	return c.streamErr
+}
+
+func (c *context) streamItem(v interface{}) error {
+	if err := c.beginStream(); err != nil {
+		return err
+	}
+	c.streamErr = c.stream.Item(v)
+	return c.streamErr
+}
+
+// streamResult write Result set by handler as stream items
+func (c *context) streamResult() error {
+	result := c.Response().Result
+	c.Response().Result = nil
+	if result == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return c.streamItem(result)
+	}
+	for idx := 0; idx < rv.Len(); idx++ {
+		if err := c.streamItem(rv.Index(idx).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *context) endStream() {
+	if err := c.beginStream(); err != nil {
+		return
+	}
+	c.streamErr = c.stream.End(c.Response())
+}
+
+// streamHead return copy of resp without Result, Page, Meta and Error
+func streamHead(resp *Response) *Response {
+	head := *resp
+	head.Result, head.Page, head.Meta, head.Error = nil, nil, nil, nil
+	return &head
+}
+
+// streamTail is part of Response written by StreamWriter End
+type streamTail struct {
+	Page  *PageInfo `json:"page,omitempty"`
+	Meta  *Meta     `json:"meta,omitempty"`
+	Error *Error    `json:"error,omitempty"`
+}
+
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+type jsonStreamWriter struct {
+	w      io.Writer
+	fields bool
+	open   bool
+}
+
+// NewJSONStreamWriter return StreamWriter which writes single JSON Response
+// with result array written item by item (suitable for chunked HTTP).
+// w is flushed after each item if it has Flush method (like http.Flusher)
+func NewJSONStreamWriter(w io.Writer) StreamWriter {
+	return &jsonStreamWriter{w: w}
+}
+
+func (s *jsonStreamWriter) Begin(resp *Response) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	s.fields = len(b) > 2
+	_, err = s.w.Write(b[:len(b)-1])
+	return err
+}
+
+func (s *jsonStreamWriter) Item(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	prefix := ","
+	if !s.open {
+		prefix = `"result":[`
+		if s.fields {
+			prefix = "," + prefix
+		}
+		s.open = true
+	}
+	if _, err = io.WriteString(s.w, prefix); err != nil {
+		return err
+	}
+	if _, err = s.w.Write(b); err != nil {
+		return err
+	}
+	return flush(s.w)
+}
+
+func (s *jsonStreamWriter) End(resp *Response) error {
+	b, err := json.Marshal(streamTail{Page: resp.Page, Meta: resp.Meta, Error: resp.Error})
+	if err != nil {
+		return err
+	}
+	buf := bytes.Buffer{}
+	if s.open {
+		buf.WriteString("]")
+	}
+	if tail := b[1 : len(b)-1]; len(tail) > 0 {
+		if s.fields || s.open {
+			buf.WriteString(",")
+		}
+		buf.Write(tail)
+	}
+	buf.WriteString("}\n")
+	if _, err = s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return flush(s.w)
+}
+
+// -----------------------------------------------------------------------------
+
+type xmlStreamWriter struct {
+	w    io.Writer
+	open bool
+}
+
+// NewXMLStreamWriter return StreamWriter which writes single XML Response
+// with items written as <result> children one by one.
+// w is flushed after each item if it has Flush method (like http.Flusher)
+func NewXMLStreamWriter(w io.Writer) StreamWriter {
+	return &xmlStreamWriter{w: w}
+}
+
+func (s *xmlStreamWriter) Begin(resp *Response) error {
+	b, err := xml.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(bytes.TrimSuffix(b, []byte("</response>")))
+	return err
+}
+
+func (s *xmlStreamWriter) Item(v interface{}) error {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !s.open {
+		if _, err = io.WriteString(s.w, "<result>"); err != nil {
+			return err
+		}
+		s.open = true
+	}
+	if _, err = s.w.Write(b); err != nil {
+		return err
+	}
+	return flush(s.w)
+}
+
+func (s *xmlStreamWriter) End(resp *Response) error {
+	buf := bytes.Buffer{}
+	if s.open {
+		buf.WriteString("</result>")
+	}
+	e := xml.NewEncoder(&buf)
+	for _, item := range []struct {
+		name string
+		v    interface{}
+	}{{"page", resp.Page}, {"meta", resp.Meta}, {"error", resp.Error}} {
+		if reflect.ValueOf(item.v).IsNil() {
+			continue
+		}
+		if err := e.EncodeElement(item.v, xml.StartElement{Name: xml.Name{Local: item.name}}); err != nil {
+			return err
+		}
+	}
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	buf.WriteString("</response>")
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return flush(s.w)
+}
+
+// -----------------------------------------------------------------------------
+
+type frameStreamWriter struct {
+	send func(*Response) error
+	head *Response
+}
+
+// NewFrameStreamWriter return StreamWriter which sends one Response per item
+// (with single item as Result) and final Response without Result.
+// It is intended for message based transports like WebSocket or TCP frames
+func NewFrameStreamWriter(send func(*Response) error) StreamWriter {
+	return &frameStreamWriter{send: send}
+}
+
+func (s *frameStreamWriter) Begin(resp *Response) error {
+	s.head = resp
+	return nil
+}
+
+func (s *frameStreamWriter) Item(v interface{}) error {
+	frame := *s.head
+	frame.Result = v
+	return s.send(&frame)
+}
+
+func (s *frameStreamWriter) End(resp *Response) error {
+	frame := *resp
+	frame.Result = nil
+	return s.send(&frame)
+}
@@ -0,0 +1,175 @@
+package sedoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type streamItem struct {
+	XMLName xml.Name `json:"-" xml:"item"`
+	ID      int      `json:"id" xml:"id,attr"`
+	Name    string   `json:"name" xml:"name,attr"`
+}
+
+func newStreamAPI() *API {
+	a := New()
+	a.AddCommand(Command{
+		Name:   "item.export",
+		Fields: []string{"id", "name"},
+		Arguments: Arguments{
+			{Name: "n", Type: ArgumentTypeInteger},
+			{Name: "fail", Type: ArgumentTypeBoolean},
+		},
+		Handler: func(c Context) error {
+			ch := make(chan streamItem)
+			go func() {
+				defer close(ch)
+				for idx := 1; idx <= c.Request().Arguments["n"].(int); idx++ {
+					ch <- streamItem{ID: idx, Name: "foo"}
+				}
+			}()
+			if err := EmitAll(c, ch); err != nil {
+				return err
+			}
+			if c.Request().Arguments["fail"] == true {
+				return c.Error(ErrInvalidArgumentValue, "fail")
+			}
+			return nil
+		},
+	})
+	a.AddCommand(Command{
+		Name: "item.list",
+		Handler: func(c Context) error {
+			c.Response().Result = []streamItem{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}
+			return nil
+		},
+	})
+	a.AddCommand(Command{
+		Name: "item.seq",
+		Handler: func(c Context) error {
+			return EmitSeq(c, func(yield func(int) bool) {
+				for idx := 0; idx < 3 && yield(idx); idx++ {
+				}
+			})
+		},
+	})
+	return a
+}
+
+func TestContext_Emit(t *testing.T) {
+	a := newStreamAPI()
+	resp := a.Execute(&Request{Command: "item.export", Arguments: InterfaceMap{"n": 2}})
+	want := []interface{}{streamItem{ID: 1, Name: "foo"}, streamItem{ID: 2, Name: "foo"}}
+	if resp.Error != nil || !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("Execute() = %v, want result %v", resp, want)
+	}
+	resp = a.Execute(&Request{Command: "item.seq"})
+	if want := []interface{}{0, 1, 2}; !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("Execute() result = %v, want %v", resp.Result, want)
+	}
+	c := &context{api: a, req: NewRequest()}
+	c.Response().Result = []streamItem{{ID: 1}}
+	if err := c.Emit(streamItem{ID: 2}); err == nil {
+		t.Errorf("Emit() into Result %T error = nil", c.Response().Result)
+	}
+	if want := []streamItem{{ID: 1}}; !reflect.DeepEqual(c.Response().Result, want) {
+		t.Errorf("Result = %v, want %v", c.Response().Result, want)
+	}
+}
+
+func TestAPI_ExecuteStream_JSON(t *testing.T) {
+	a := newStreamAPI()
+	tests := []struct {
+		name string
+		req  *Request
+		want string
+	}{
+		{"items", &Request{Command: "item.export", Arguments: InterfaceMap{"n": 2}},
+			`{"result":[{"id":1,"name":"foo"},{"id":2,"name":"foo"}]}`},
+		{"fields", &Request{Command: "item.export", Arguments: InterfaceMap{"n": 1, "fields": []interface{}{"id"}}},
+			`{"result":[{"id":1}]}`},
+		{"empty", &Request{Command: "item.export", Arguments: InterfaceMap{"n": 0}}, `{}`},
+		{"result", &Request{Command: "item.list"}, `{"result":[{"id":1,"name":"foo"},{"id":2,"name":"bar"}]}`},
+		{"error after items", &Request{Command: "item.export", Arguments: InterfaceMap{"n": 1, "fail": true}},
+			`{"result":[{"id":1,"name":"foo"}],"error":{"code":8,"desc":"invalid command argument parameter value: fail"}}`},
+		{"invalid request", &Request{Command: "unknown"}, `{"error":{"code":3,"desc":"unknown command"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := a.ExecuteStream(tt.req, NewJSONStreamWriter(&buf)); err != nil {
+				t.Fatalf("ExecuteStream() error = %v", err)
+			}
+			got := map[string]interface{}{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("invalid json %s: %v", buf.String(), err)
+			}
+			want := map[string]interface{}{}
+			_ = json.Unmarshal([]byte(tt.want), &want)
+			delete(got, "datetime")
+			delete(got, "command")
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ExecuteStream() = %s, want %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAPI_ExecuteStream_XML(t *testing.T) {
+	a := newStreamAPI()
+	buf := bytes.Buffer{}
+	if err := a.ExecuteStream(&Request{Command: "item.export", Arguments: InterfaceMap{"n": 2, "fail": true}}, NewXMLStreamWriter(&buf)); err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	want := `<result><item id="1" name="foo"></item><item id="2" name="foo"></item></result><error code="8" desc="invalid command argument parameter value: fail"></error></response>`
+	if !strings.HasPrefix(buf.String(), "<response") || !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("ExecuteStream() = %s, want suffix %s", buf.String(), want)
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &struct{}{}); err != nil {
+		t.Errorf("invalid xml %s: %v", buf.String(), err)
+	}
+}
+
+func TestAPI_ExecuteStream_frames(t *testing.T) {
+	a := newStreamAPI()
+	frames := []*Response{}
+	w := NewFrameStreamWriter(func(r *Response) error {
+		frames = append(frames, r)
+		return nil
+	})
+	if err := a.ExecuteStream(&Request{ID: "42", Command: "item.export", Arguments: InterfaceMap{"n": 2}}, w); err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(frames))
+	}
+	for idx, frame := range frames {
+		if frame.ID != "42" || frame.Command != "item.export" {
+			t.Errorf("frame %d = %v", idx, frame)
+		}
+	}
+	if frames[1].Result != (streamItem{ID: 2, Name: "foo"}) || frames[2].Result != nil || frames[2].Error != nil {
+		t.Errorf("frames = %v, %v", frames[1], frames[2])
+	}
+}
+
+func TestAPI_ExecuteStream_writeError(t *testing.T) {
+	a := newStreamAPI()
+	errWrite := errors.New("connection closed")
+	sent := 0
+	w := NewFrameStreamWriter(func(r *Response) error {
+		sent++
+		return errWrite
+	})
+	if err := a.ExecuteStream(&Request{Command: "item.export", Arguments: InterfaceMap{"n": 10}}, w); err != errWrite {
+		t.Errorf("ExecuteStream() error = %v, want %v", err, errWrite)
+	}
+	if sent != 1 {
+		t.Errorf("sent %d frames after write error, want 1", sent)
+	}
+}
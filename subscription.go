@@ -0,0 +1,163 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// CommandUnsubscribe is name of Command added to API with first subscription Command
+const CommandUnsubscribe = "unsubscribe"
+
+// Hub is in-process publish/subscribe hub of events. It is safe for concurrent use
+type Hub struct {
+	// Buffer is size of subscriber events channel. Events published to
+	// subscriber with full buffer are dropped
+	Buffer int
+	mu     sync.RWMutex
+	topics map[string]map[chan interface{}]struct{}
+}
+
+// NewHub is Hub constructor
+func NewHub(buffer int) *Hub {
+	return &Hub{Buffer: buffer, topics: map[string]map[chan interface{}]struct{}{}}
+}
+
+// Subscribe return channel of events published to topic and func which
+// cancels subscription and closes channel
+func (h *Hub) Subscribe(topic string) (<-chan interface{}, func()) {
+	ch := make(chan interface{}, h.Buffer)
+	h.mu.Lock()
+	if h.topics == nil {
+		h.topics = map[string]map[chan interface{}]struct{}{}
+	}
+	if h.topics[topic] == nil {
+		h.topics[topic] = map[chan interface{}]struct{}{}
+	}
+	h.topics[topic][ch] = struct{}{}
+	h.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.topics[topic], ch)
+			if len(h.topics[topic]) == 0 {
+				delete(h.topics, topic)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish event to topic subscribers, return count of subscribers event delivered to
+func (h *Hub) Publish(topic string, event interface{}) (delivered int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.topics[topic] {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+		}
+	}
+	return
+}
+
+// Serve emit events published to topic until Context of execution is done.
+// It is intended to be used as subscription Command Handler body
+func (h *Hub) Serve(c Context, topic string) error {
+	events, cancel := h.Subscribe(topic)
+	defer cancel()
+	done := c.StdContext().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		case event := <-events:
+			if err := c.Emit(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type subscriptions struct {
+	mu     sync.Mutex
+	active map[string]activeSubscription
+}
+
+type activeSubscription struct {
+	session string
+	cancel  stdcontext.CancelFunc
+}
+
+// subscribe register subscription of c with server generated identifier:
+// set Response Subscription and replace context of execution by cancelable
+// one. Returned func unregister it
+func (api *API) subscribe(c *context) (func(), error) {
+	if c.stream == nil {
+		return nil, api.NewError(ErrStreamRequired)
+	}
+	id := uuid.New().String()
+	ctx, cancel := stdcontext.WithCancel(c.StdContext())
+	if api.subscriptions != nil {
+		api.subscriptions.mu.Lock()
+		api.subscriptions.active[id] = activeSubscription{session: c.Request().Session, cancel: cancel}
+		api.subscriptions.mu.Unlock()
+	}
+	c.ctx = ctx
+	c.Response().Subscription = id
+	return func() {
+		cancel()
+		if api.subscriptions != nil {
+			api.subscriptions.mu.Lock()
+			delete(api.subscriptions.active, id)
+			api.subscriptions.mu.Unlock()
+		}
+	}, nil
+}
+
+// Unsubscribe cancel active subscription of any session, return false if
+// subscription not found
+func (api *API) Unsubscribe(id string) bool {
+	return api.unsubscribe(id, func(string) bool { return true })
+}
+
+// unsubscribe cancel active subscription if its session is allowed
+func (api *API) unsubscribe(id string, allowed func(session string) bool) bool {
+	if api.subscriptions == nil {
+		return false
+	}
+	api.subscriptions.mu.Lock()
+	defer api.subscriptions.mu.Unlock()
+	sub, ok := api.subscriptions.active[id]
+	if !ok || !allowed(sub.session) {
+		return false
+	}
+	sub.cancel()
+	delete(api.subscriptions.active, id)
+	return true
+}
+
+func (api *API) unsubscribeCommand() Command {
+	return Command{
+		Name:        CommandUnsubscribe,
+		Description: "Cancel subscription",
+		Arguments: Arguments{
+			{Name: "subscription", Type: ArgumentTypeString, Required: true, Description: "Subscription identifier"},
+		},
+		Handler: func(c Context) error {
+			id, _ := c.Request().Arguments["subscription"].(string)
+			// subscription without session can be canceled by API Unsubscribe only
+			session := c.Request().Session
+			if len(session) == 0 || !api.unsubscribe(id, func(s string) bool { return s == session }) {
+				return c.Error(ErrInvalidArgumentValue, "unknown subscription", id)
+			}
+			return nil
+		},
+	}
+}
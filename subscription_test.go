@@ -0,0 +1,161 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHub(t *testing.T) {
+	h := NewHub(1)
+	foo, cancelFoo := h.Subscribe("foo")
+	bar, cancelBar := h.Subscribe("bar")
+	defer cancelBar()
+	if n := h.Publish("foo", 1); n != 1 {
+		t.Errorf("Publish() = %d, want 1", n)
+	}
+	if n := h.Publish("foo", 2); n != 0 {
+		t.Errorf("Publish() to full subscriber = %d, want 0", n)
+	}
+	if v := <-foo; v != 1 {
+		t.Errorf("event = %v, want 1", v)
+	}
+	select {
+	case v := <-bar:
+		t.Errorf("unexpected event %v", v)
+	default:
+	}
+	cancelFoo()
+	cancelFoo()
+	if _, ok := <-foo; ok {
+		t.Error("channel is not closed after cancel")
+	}
+	if n := h.Publish("foo", 3); n != 0 {
+		t.Errorf("Publish() after cancel = %d, want 0", n)
+	}
+}
+
+func newSubscriptionAPI(h *Hub) *API {
+	a := New()
+	a.AddCommand(Command{
+		Name:   "order.watch",
+		Kind:   CommandKindSubscription,
+		Events: Arguments{{Name: "id", Type: ArgumentTypeInteger, Description: "Order identifier"}},
+		Handler: func(c Context) error {
+			return h.Serve(c, "orders")
+		},
+	})
+	return a
+}
+
+func TestAPI_subscription(t *testing.T) {
+	h := NewHub(8)
+	a := newSubscriptionAPI(h)
+	if !a.Commands.Contains(CommandUnsubscribe) {
+		t.Fatal("unsubscribe command is not added")
+	}
+	const session = "01234567-89ab-cdef-0123-456789abcdef"
+	mu := sync.Mutex{}
+	frames := []*Response{}
+	w := NewFrameStreamWriter(func(r *Response) error {
+		mu.Lock()
+		defer mu.Unlock()
+		frames = append(frames, r)
+		return nil
+	})
+	done := make(chan error)
+	go func() {
+		done <- a.ExecuteStream(&Request{ID: "1", Session: session, Command: "order.watch"}, w)
+	}()
+	for h.Publish("orders", InterfaceMap{"id": 1}) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	h.Publish("orders", InterfaceMap{"id": 2})
+	if resp := a.Execute(&Request{Command: "order.watch"}); resp.Error == nil || resp.Error.Code != ErrStreamRequired {
+		t.Errorf("Execute() error = %v, want %d", resp.Error, ErrStreamRequired)
+	}
+	for {
+		mu.Lock()
+		n := len(frames)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	id := frames[0].Subscription
+	mu.Unlock()
+	if len(id) == 0 || id == "1" {
+		t.Fatalf("subscription = %q, want server generated identifier", id)
+	}
+	for _, req := range []*Request{
+		{Command: CommandUnsubscribe, Arguments: InterfaceMap{"subscription": "1"}, Session: session},
+		{Command: CommandUnsubscribe, Arguments: InterfaceMap{"subscription": id}},
+	} {
+		if resp := a.Execute(req); resp.Error == nil {
+			t.Errorf("unsubscribe %v of another client succeeded", req.Arguments)
+		}
+	}
+	if resp := a.Execute(&Request{Command: CommandUnsubscribe, Arguments: InterfaceMap{"subscription": id}, Session: session}); resp.Error != nil {
+		t.Fatalf("unsubscribe error = %v", resp.Error)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(frames))
+	}
+	for idx, frame := range frames {
+		if frame.Subscription != id || frame.Error != nil {
+			t.Errorf("frame %d = %v", idx, frame)
+		}
+	}
+	if frames[1].Result.(InterfaceMap)["id"] != 2 {
+		t.Errorf("frame 1 result = %v", frames[1].Result)
+	}
+	if resp := a.Execute(&Request{Command: CommandUnsubscribe, Arguments: InterfaceMap{"subscription": id}, Session: session}); resp.Error == nil {
+		t.Error("unsubscribe of finished subscription succeeded")
+	}
+}
+
+func TestAPI_unsubscribeSessionless(t *testing.T) {
+	a := newSubscriptionAPI(NewHub(0))
+	done := make(chan error)
+	go func() {
+		done <- a.ExecuteStream(&Request{Command: "order.watch"}, NewFrameStreamWriter(func(*Response) error { return nil }))
+	}()
+	var id string
+	for len(id) == 0 {
+		a.subscriptions.mu.Lock()
+		for key := range a.subscriptions.active {
+			id = key
+		}
+		a.subscriptions.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	if resp := a.Execute(&Request{Command: CommandUnsubscribe, Arguments: InterfaceMap{"subscription": id}}); resp.Error == nil {
+		t.Error("unsubscribe without session succeeded")
+	}
+	if !a.Unsubscribe(id) {
+		t.Fatal("API.Unsubscribe() = false")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+}
+
+func TestAPI_subscriptionContext(t *testing.T) {
+	a := newSubscriptionAPI(NewHub(0))
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 10*time.Millisecond)
+	defer cancel()
+	var last *Response
+	err := a.ExecuteStreamContext(ctx, &Request{Command: "order.watch"}, NewFrameStreamWriter(func(r *Response) error {
+		last = r
+		return nil
+	}))
+	if err != nil || last == nil || len(last.Subscription) == 0 || last.Error != nil {
+		t.Errorf("ExecuteStreamContext() = %v, last frame %v", err, last)
+	}
+}
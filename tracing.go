@@ -0,0 +1,107 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Attribute keys of spans created by API
+const (
+	AttributeCommand   = "sedoc.command"
+	AttributeRequestID = "sedoc.request_id"
+	AttributeSession   = "sedoc.session"
+	AttributeErrorCode = "sedoc.error_code"
+)
+
+// Attribute is span attribute
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is tracing span (compatible with OpenTelemetry trace.Span subset)
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer creates spans. API creates span per Execute and child spans per
+// middleware and handler. Adapter for OpenTelemetry is expected to wrap
+// trace.Tracer and propagation.TraceContext
+type Tracer interface {
+	// Start span as child of span in ctx (if any), return ctx with span
+	Start(ctx stdcontext.Context, name string, attrs ...Attribute) (stdcontext.Context, Span)
+	// Extract parent span context from W3C traceparent value (Request Trace
+	// or transport header), return ctx as is if traceparent is invalid
+	Extract(ctx stdcontext.Context, traceparent string) stdcontext.Context
+}
+
+// startSpan start root span of execution of c, returned func ends it and
+// restores context of execution
+func (api *API) startSpan(c *context) func() {
+	if api.Tracer == nil {
+		return func() {}
+	}
+	req := c.Request()
+	parent := c.ctx
+	ctx := c.StdContext()
+	if len(req.Trace) > 0 {
+		ctx = api.Tracer.Extract(ctx, req.Trace)
+	}
+	ctx, span := api.Tracer.Start(ctx, "sedoc.execute "+req.Command,
+		Attribute{AttributeCommand, req.Command},
+		Attribute{AttributeRequestID, req.ID},
+		Attribute{AttributeSession, req.Session},
+	)
+	c.ctx = ctx
+	return func() {
+		c.ctx = parent
+		code := 0
+		if err := c.Response().Error; err != nil {
+			code = err.Code
+			span.RecordError(err)
+		}
+		span.SetAttributes(Attribute{AttributeErrorCode, code})
+		span.End()
+	}
+}
+
+// wrap next by middleware, adding spans around each middleware and next
+// (named by name) if API Tracer is set
+func (api *API) wrap(c *context, name string, next HandlerFunc, middleware ...MiddlewareFunc) HandlerFunc {
+	if api.Tracer == nil {
+		for idx := len(middleware) - 1; idx >= 0; idx-- {
+			next = middleware[idx](next)
+		}
+		return next
+	}
+	next = api.traced(c, name, next)
+	for idx := len(middleware) - 1; idx >= 0; idx-- {
+		next = api.traced(c, "sedoc.middleware "+funcName(middleware[idx]), middleware[idx](next))
+	}
+	return next
+}
+
+func (api *API) traced(c *context, name string, next HandlerFunc) HandlerFunc {
+	return func(cc Context) (err error) {
+		parent := c.ctx
+		ctx, span := api.Tracer.Start(c.StdContext(), name, Attribute{AttributeCommand, c.Request().Command})
+		c.ctx = ctx
+		defer func() {
+			c.ctx = parent
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		}()
+		return next(cc)
+	}
+}
+
+func funcName(f interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
@@ -0,0 +1,144 @@
+/*
+Package tracing is in-memory implementation of sedoc Tracer. It records
+ended spans and is intended for tests and debugging; production services
+are expected to use OpenTelemetry adapter.
+*/
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+)
+
+// SpanContext identifies span
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// Valid return true if TraceID and SpanID are set
+func (sc SpanContext) Valid() bool {
+	return len(sc.TraceID) == 32 && len(sc.SpanID) == 16
+}
+
+// Traceparent return W3C traceparent value
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// SpanData is recorded span
+type SpanData struct {
+	SpanContext
+	Name       string
+	ParentID   string
+	Attributes map[string]interface{}
+	Errors     []error
+	Start      time.Time
+	End        time.Time
+}
+
+type spanContextKey struct{}
+
+// FromContext return SpanContext of span (or extracted remote parent) in ctx
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// Recorder is sedoc Tracer which records ended spans. It is safe for concurrent use
+type Recorder struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewRecorder is Recorder constructor
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start span as child of span in ctx
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...sedoc.Attribute) (context.Context, sedoc.Span) {
+	s := &span{recorder: r, data: SpanData{Name: name, Attributes: map[string]interface{}{}, Start: time.Now()}}
+	if parent, ok := FromContext(ctx); ok {
+		s.data.TraceID, s.data.ParentID = parent.TraceID, parent.SpanID
+	} else {
+		s.data.TraceID = randomID(16)
+	}
+	s.data.SpanID = randomID(8)
+	s.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanContextKey{}, s.data.SpanContext), s
+}
+
+var traceparentRegexp = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// Extract remote parent SpanContext from W3C traceparent value
+func (r *Recorder) Extract(ctx context.Context, traceparent string) context.Context {
+	m := traceparentRegexp.FindStringSubmatch(traceparent)
+	if m == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, SpanContext{TraceID: m[1], SpanID: m[2]})
+}
+
+// Spans return recorded spans in order of their end
+func (r *Recorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]SpanData{}, r.spans...)
+}
+
+// Reset remove recorded spans
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+type span struct {
+	recorder *Recorder
+	mu       sync.Mutex
+	data     SpanData
+	ended    bool
+}
+
+func (s *span) SetAttributes(attrs ...sedoc.Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *span) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Errors = append(s.data.Errors, err)
+}
+
+func (s *span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.recorder.mu.Lock()
+	s.recorder.spans = append(s.recorder.spans, data)
+	s.recorder.mu.Unlock()
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
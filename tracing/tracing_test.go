@@ -0,0 +1,96 @@
+package tracing_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sedoc "github.com/nsemikov/go-sedoc"
+	"github.com/nsemikov/go-sedoc/tracing"
+)
+
+func newAPI(r *tracing.Recorder) *sedoc.API {
+	api := sedoc.New()
+	api.Tracer = r
+	pass := func(next sedoc.HandlerFunc) sedoc.HandlerFunc { return next }
+	api.Pre(pass)
+	api.Use(pass)
+	api.AddCommand(sedoc.Command{
+		Name:      "user.get",
+		Arguments: sedoc.Arguments{{Name: "fail", Type: sedoc.ArgumentTypeBoolean}},
+		Handler: func(c sedoc.Context) error {
+			if sc, ok := tracing.FromContext(c.StdContext()); !ok || !sc.Valid() {
+				return errors.New("no span in handler context")
+			}
+			if c.Request().Arguments["fail"] == true {
+				return c.Error(sedoc.ErrInvalidArgumentValue)
+			}
+			return nil
+		},
+	})
+	return api
+}
+
+func TestRecorder(t *testing.T) {
+	r := tracing.NewRecorder()
+	api := newAPI(r)
+	const traceID, parentID = "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331"
+	resp := api.Execute(&sedoc.Request{ID: "42", Command: "user.get", Trace: "00-" + traceID + "-" + parentID + "-01"})
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	spans := r.Spans()
+	names := []string{"sedoc.handler", "sedoc.middleware", "sedoc.process", "sedoc.middleware", "sedoc.execute user.get"}
+	if len(spans) != len(names) {
+		t.Fatalf("got %d spans, want %d: %+v", len(spans), len(names), spans)
+	}
+	for idx, s := range spans {
+		if len(s.Name) < len(names[idx]) || s.Name[:len(names[idx])] != names[idx] {
+			t.Errorf("span %d name = %s, want %s", idx, s.Name, names[idx])
+		}
+		if s.TraceID != traceID {
+			t.Errorf("span %d trace = %s, want %s", idx, s.TraceID, traceID)
+		}
+		if idx+1 < len(spans) && s.ParentID != spans[idx+1].SpanID {
+			t.Errorf("span %d parent = %s, want %s", idx, s.ParentID, spans[idx+1].SpanID)
+		}
+	}
+	root := spans[len(spans)-1]
+	if root.ParentID != parentID || root.Attributes[sedoc.AttributeCommand] != "user.get" ||
+		root.Attributes[sedoc.AttributeRequestID] != "42" || root.Attributes[sedoc.AttributeErrorCode] != 0 {
+		t.Errorf("root span = %+v", root)
+	}
+}
+
+func TestRecorder_error(t *testing.T) {
+	r := tracing.NewRecorder()
+	api := newAPI(r)
+	api.Execute(&sedoc.Request{Command: "user.get", Arguments: sedoc.InterfaceMap{"fail": true}})
+	spans := r.Spans()
+	root := spans[len(spans)-1]
+	if root.ParentID != "" || root.Attributes[sedoc.AttributeErrorCode] != sedoc.ErrInvalidArgumentValue || len(root.Errors) != 1 {
+		t.Errorf("root span = %+v", root)
+	}
+	if len(spans[0].Errors) != 1 {
+		t.Errorf("handler span = %+v", spans[0])
+	}
+	r.Reset()
+	api.Execute(&sedoc.Request{Command: "unknown", Trace: "invalid"})
+	if spans = r.Spans(); len(spans) != 3 || spans[2].Attributes[sedoc.AttributeErrorCode] != sedoc.ErrUnknownCommand {
+		t.Errorf("spans of unknown command = %+v", spans)
+	}
+}
+
+func TestRecorder_httpHeader(t *testing.T) {
+	r := tracing.NewRecorder()
+	api := newAPI(r)
+	const traceID, parentID = "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331"
+	hr := httptest.NewRequest(http.MethodGet, "/", nil)
+	hr.Header.Set(sedoc.HeaderTraceparent, "00-"+traceID+"-"+parentID+"-01")
+	api.Execute(api.RequestFromHTTP(&sedoc.Request{Command: "user.get"}, hr))
+	spans := r.Spans()
+	if root := spans[len(spans)-1]; root.TraceID != traceID || root.ParentID != parentID {
+		t.Errorf("root span = %+v", root)
+	}
+}
@@ -0,0 +1,42 @@
+package sedoc
+
+import (
+	stdcontext "context"
+	"testing"
+)
+
+type spanKey struct{}
+
+type stubSpan struct{}
+
+func (stubSpan) SetAttributes(...Attribute) {}
+func (stubSpan) RecordError(error)          {}
+func (stubSpan) End()                       {}
+
+type stubTracer struct{}
+
+func (stubTracer) Start(ctx stdcontext.Context, name string, _ ...Attribute) (stdcontext.Context, Span) {
+	return stdcontext.WithValue(ctx, spanKey{}, name), stubSpan{}
+}
+
+func (stubTracer) Extract(ctx stdcontext.Context, _ string) stdcontext.Context { return ctx }
+
+func TestAPI_tracingRestoresContext(t *testing.T) {
+	a := New()
+	a.Tracer = stubTracer{}
+	var inner interface{}
+	a.AddCommand(Command{Name: "foo", Handler: func(c Context) error {
+		inner = c.StdContext().Value(spanKey{})
+		return nil
+	}})
+	type key struct{}
+	ctx := stdcontext.WithValue(stdcontext.Background(), key{}, "parent")
+	c := &context{ctx: ctx, api: a, req: &Request{Command: "foo"}}
+	a.execute(c)
+	if inner != "sedoc.handler" {
+		t.Errorf("handler span = %v, want sedoc.handler", inner)
+	}
+	if c.ctx != ctx {
+		t.Errorf("context of execution is not restored: span %v", c.ctx.Value(spanKey{}))
+	}
+}
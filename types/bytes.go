@@ -0,0 +1,56 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+)
+
+// Bytes type is binary data encoded as base64 string
+type Bytes []byte
+
+// ParseBytes decode standard or URL base64 string (padded or not)
+func ParseBytes(s string) (Bytes, error) {
+	var err error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		var b []byte
+		if b, err = enc.DecodeString(s); err == nil {
+			return Bytes(b), nil
+		}
+	}
+	return nil, err
+}
+
+// String method
+func (b Bytes) String() string {
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// MarshalText method
+func (b Bytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText method
+func (b *Bytes) UnmarshalText(text []byte) (err error) {
+	*b, err = ParseBytes(string(text))
+	return
+}
+
+// Scan method
+func (b *Bytes) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case []byte:
+		*b = append(Bytes{}, value...)
+	case string:
+		*b, err = ParseBytes(value)
+	default:
+		err = fmt.Errorf("can not convert '%v' to Bytes", value)
+	}
+	return
+}
+
+// Value method
+func (b Bytes) Value() (driver.Value, error) {
+	return []byte(b), nil
+}
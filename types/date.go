@@ -0,0 +1,88 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// DateLayout is layout of Date string representation (ISO 8601 calendar date)
+const DateLayout = "2006-01-02"
+
+// Date type is calendar date without time and location
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf return Date of t in t location
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate func
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// String method
+func (d Date) String() string {
+	return d.In(time.UTC).Format(DateLayout)
+}
+
+// In return time of midnight of Date in location loc
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero method
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Before method
+func (d Date) Before(o Date) bool {
+	return d.In(time.UTC).Before(o.In(time.UTC))
+}
+
+// After method
+func (d Date) After(o Date) bool {
+	return d.In(time.UTC).After(o.In(time.UTC))
+}
+
+// MarshalText method
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText method
+func (d *Date) UnmarshalText(b []byte) (err error) {
+	*d, err = ParseDate(string(b))
+	return
+}
+
+// Scan method
+func (d *Date) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case time.Time:
+		*d = DateOf(value)
+	case string:
+		*d, err = ParseDate(value)
+	case []byte:
+		*d, err = ParseDate(string(value))
+	default:
+		err = fmt.Errorf("can not convert '%v' to Date", value)
+	}
+	return
+}
+
+// Value method
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale is limit of ParseDecimal exponent and of resulting scale
+// (digits after or padding zeros before decimal point)
+const MaxDecimalScale = 400
+
+// Decimal type is arbitrary-precision decimal number (for money etc).
+// Zero value is 0
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// NewDecimal return unscaled * 10^(-scale)
+func NewDecimal(unscaled int64, scale int) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// ParseDecimal parse decimal string like "-123.4500" or "1.5e3"
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	exp := 0
+	if idx := strings.IndexAny(str, "eE"); idx >= 0 {
+		var err error
+		if exp, err = strconv.Atoi(str[idx+1:]); err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+		}
+		if exp > MaxDecimalScale || exp < -MaxDecimalScale {
+			return Decimal{}, fmt.Errorf("invalid decimal: %q: exponent out of range", s)
+		}
+		str = str[:idx]
+	}
+	scale := 0
+	if idx := strings.IndexByte(str, '.'); idx >= 0 {
+		scale = len(str) - idx - 1
+		str = str[:idx] + str[idx+1:]
+	}
+	if len(strings.TrimLeft(str, "+-")) == 0 || strings.ContainsAny(strings.TrimLeft(str, "+-"), "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	scale -= exp
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q: scale out of range", s)
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: scale}, nil
+}
+
+// MustParseDecimal func
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Scale return count of digits after decimal point
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Rat return Decimal as big.Rat
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+}
+
+// Float64 return nearest float64 value
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp compare d and o, return -1, 0 or +1
+func (d Decimal) Cmp(o Decimal) int {
+	return d.Rat().Cmp(o.Rat())
+}
+
+// Sign return -1, 0 or +1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// String method
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalText method
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText method
+func (d *Decimal) UnmarshalText(b []byte) (err error) {
+	*d, err = ParseDecimal(string(b))
+	return
+}
+
+// MarshalJSON method. Decimal is marshaled as string to keep precision
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON method. Both JSON number and string are accepted
+func (d *Decimal) UnmarshalJSON(b []byte) (err error) {
+	if string(b) == "null" {
+		return nil
+	}
+	*d, err = ParseDecimal(strings.Trim(string(b), `"`))
+	return
+}
+
+// Scan method
+func (d *Decimal) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case string:
+		*d, err = ParseDecimal(value)
+	case []byte:
+		*d, err = ParseDecimal(string(value))
+	case int64:
+		*d = NewDecimal(value, 0)
+	case float64:
+		*d, err = ParseDecimal(fmt.Sprint(value))
+	default:
+		err = fmt.Errorf("can not convert '%v' to Decimal", value)
+	}
+	return
+}
+
+// Value method
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
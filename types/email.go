@@ -0,0 +1,73 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Email type is email address without display name
+type Email string
+
+// ParseEmail func
+func ParseEmail(s string) (Email, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", err
+	}
+	if addr.Address != s {
+		return "", fmt.Errorf("mail: not a bare address: %q", s)
+	}
+	return Email(addr.Address), nil
+}
+
+// String method
+func (e Email) String() string {
+	return string(e)
+}
+
+// Local return local part of address
+func (e Email) Local() string {
+	if idx := strings.LastIndexByte(string(e), '@'); idx >= 0 {
+		return string(e[:idx])
+	}
+	return string(e)
+}
+
+// Domain return domain part of address
+func (e Email) Domain() string {
+	if idx := strings.LastIndexByte(string(e), '@'); idx >= 0 {
+		return string(e[idx+1:])
+	}
+	return ""
+}
+
+// MarshalText method
+func (e Email) MarshalText() ([]byte, error) {
+	return []byte(e), nil
+}
+
+// UnmarshalText method
+func (e *Email) UnmarshalText(b []byte) (err error) {
+	*e, err = ParseEmail(string(b))
+	return
+}
+
+// Scan method
+func (e *Email) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case string:
+		*e, err = ParseEmail(value)
+	case []byte:
+		*e, err = ParseEmail(string(value))
+	default:
+		err = fmt.Errorf("can not convert '%v' to Email", value)
+	}
+	return
+}
+
+// Value method
+func (e Email) Value() (driver.Value, error) {
+	return string(e), nil
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/netip"
+)
+
+// IP type is a wrapper for net/netip Addr with sql Scan and Value methods
+type IP struct {
+	netip.Addr
+}
+
+// ParseIP func
+func ParseIP(s string) (IP, error) {
+	addr, err := netip.ParseAddr(s)
+	return IP{addr}, err
+}
+
+// Scan method
+func (ip *IP) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case string:
+		*ip, err = ParseIP(value)
+	case []byte:
+		*ip, err = ParseIP(string(value))
+	default:
+		err = fmt.Errorf("can not convert '%v' to IP", value)
+	}
+	return
+}
+
+// Value method
+func (ip IP) Value() (driver.Value, error) {
+	return ip.String(), nil
+}
+
+// CIDR type is a wrapper for net/netip Prefix with sql Scan and Value methods
+type CIDR struct {
+	netip.Prefix
+}
+
+// ParseCIDR func
+func ParseCIDR(s string) (CIDR, error) {
+	prefix, err := netip.ParsePrefix(s)
+	return CIDR{prefix}, err
+}
+
+// Scan method
+func (c *CIDR) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case string:
+		*c, err = ParseCIDR(value)
+	case []byte:
+		*c, err = ParseCIDR(string(value))
+	default:
+		err = fmt.Errorf("can not convert '%v' to CIDR", value)
+	}
+	return
+}
+
+// Value method
+func (c CIDR) Value() (driver.Value, error) {
+	return c.String(), nil
+}
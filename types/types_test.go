@@ -0,0 +1,131 @@
+package types_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/nsemikov/go-sedoc/types"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type holder struct {
+	XMLName xml.Name      `json:"-" xml:"holder" yaml:"-"`
+	Date    types.Date    `json:"date" xml:"date,attr" yaml:"date"`
+	Decimal types.Decimal `json:"decimal" xml:"decimal" yaml:"decimal"`
+	Email   types.Email   `json:"email" xml:"email,attr" yaml:"email"`
+	URL     types.URL     `json:"url" xml:"url" yaml:"url"`
+	IP      types.IP      `json:"ip" xml:"ip,attr" yaml:"ip"`
+	CIDR    types.CIDR    `json:"cidr" xml:"cidr" yaml:"cidr"`
+	Bytes   types.Bytes   `json:"bytes" xml:"bytes" yaml:"bytes"`
+}
+
+func newHolder(t *testing.T) holder {
+	h := holder{}
+	var err error
+	if h.Date, err = types.ParseDate("2018-10-16"); err != nil {
+		t.Fatal(err)
+	}
+	h.Decimal = types.MustParseDecimal("-1234.050")
+	if h.Email, err = types.ParseEmail("user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if h.URL, err = types.ParseURL("https://example.com/a?b=c"); err != nil {
+		t.Fatal(err)
+	}
+	if h.IP, err = types.ParseIP("2001:db8::1"); err != nil {
+		t.Fatal(err)
+	}
+	if h.CIDR, err = types.ParseCIDR("192.0.2.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	h.Bytes = types.Bytes("sedoc")
+	return h
+}
+
+func TestTypes_roundtrip(t *testing.T) {
+	formats := []struct {
+		name      string
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"json", json.Marshal, json.Unmarshal},
+		{"xml", xml.Marshal, xml.Unmarshal},
+		{"yaml", yaml.Marshal, yaml.Unmarshal},
+	}
+	want := newHolder(t)
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			b, err := f.marshal(want)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			got := holder{}
+			if err = f.unmarshal(b, &got); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", b, err)
+			}
+			got.XMLName = want.XMLName
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", b, got, want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"0", "0", false},
+		{"12.50", "12.50", false},
+		{"-0.05", "-0.05", false},
+		{".5", "0.5", false},
+		{"+3", "3", false},
+		{"1.5e3", "1500", false},
+		{"15e-3", "0.015", false},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789", false},
+		{"", "", true},
+		{"1.2.3", "", true},
+		{"1-2", "", true},
+		{"1e", "", true},
+		{"foo", "", true},
+		{"1e3x", "", true},
+		{"1e+2", "100", false},
+		{"1e50000000", "", true},
+		{"1e-50000000", "", true},
+		{"1e401", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := types.ParseDecimal(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParseDecimal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if types.MustParseDecimal("1.50").Cmp(types.NewDecimal(15, 1)) != 0 {
+		t.Errorf("Decimal.Cmp() 1.50 != 1.5")
+	}
+	if (types.Decimal{}).String() != "0" {
+		t.Errorf("Decimal{}.String() = %v, want 0", types.Decimal{})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	e, err := types.ParseEmail("user.name@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Local() != "user.name" || e.Domain() != "example.com" {
+		t.Errorf("Email parts = %s, %s", e.Local(), e.Domain())
+	}
+	if _, err = types.ParseEmail("User <user@example.com>"); err == nil {
+		t.Errorf("ParseEmail() with display name want error, but not")
+	}
+}
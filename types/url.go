@@ -0,0 +1,53 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+)
+
+// URL type is absolute URL (with scheme)
+type URL struct {
+	url.URL
+}
+
+// ParseURL func
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+	if !u.IsAbs() {
+		return URL{}, fmt.Errorf("url: not an absolute url: %q", s)
+	}
+	return URL{*u}, nil
+}
+
+// MarshalText method
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText method
+func (u *URL) UnmarshalText(b []byte) (err error) {
+	*u, err = ParseURL(string(b))
+	return
+}
+
+// Scan method
+func (u *URL) Scan(value interface{}) (err error) {
+	switch value := value.(type) {
+	case string:
+		*u, err = ParseURL(value)
+	case []byte:
+		*u, err = ParseURL(string(value))
+	default:
+		err = fmt.Errorf("can not convert '%v' to URL", value)
+	}
+	return
+}
+
+// Value method
+func (u URL) Value() (driver.Value, error) {
+	return u.String(), nil
+}
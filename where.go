@@ -0,0 +1,317 @@
+package sedoc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Operator is Where condition operator. In request Where item operator is
+// set by key suffix like "created[gt]" or by nested object like
+// {"created": {"gt": "2018-10-16T09:58:03Z"}}. Key without suffix means OperatorEq
+type Operator string
+
+const (
+	// OperatorEq means field equals value (or one of values for Multiple Argument)
+	OperatorEq Operator = "eq"
+	// OperatorNe means field not equals value
+	OperatorNe Operator = "ne"
+	// OperatorLt means field less than value
+	OperatorLt Operator = "lt"
+	// OperatorLe means field less than or equals value
+	OperatorLe Operator = "le"
+	// OperatorGt means field greater than value
+	OperatorGt Operator = "gt"
+	// OperatorGe means field greater than or equals value
+	OperatorGe Operator = "ge"
+	// OperatorIn means field equals one of list values
+	OperatorIn Operator = "in"
+	// OperatorLike means field matches SQL LIKE pattern (% and _ wildcards)
+	OperatorLike Operator = "like"
+	// OperatorNull means field is null (value true) or is not null (value false)
+	OperatorNull Operator = "null"
+	// OperatorBetween means field between two list values (inclusive)
+	OperatorBetween Operator = "between"
+)
+
+// Operators is list of Operator
+type Operators []Operator
+
+// ComparisonOperators is list of Operator applicable for ordered types
+var ComparisonOperators = Operators{OperatorEq, OperatorNe, OperatorLt, OperatorLe, OperatorGt, OperatorGe, OperatorIn, OperatorBetween}
+
+// Contains Operator
+func (ops Operators) Contains(op Operator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// String method
+func (ops Operators) String() string {
+	s := make([]string, len(ops))
+	for idx := range ops {
+		s[idx] = string(ops[idx])
+	}
+	return strings.Join(s, " ")
+}
+
+// MarshalText method (used for XML attribute)
+func (ops Operators) MarshalText() ([]byte, error) {
+	return []byte(ops.String()), nil
+}
+
+// UnmarshalText method (used for XML attribute)
+func (ops *Operators) UnmarshalText(b []byte) error {
+	*ops = Operators{}
+	for _, s := range strings.Fields(string(b)) {
+		*ops = append(*ops, Operator(s))
+	}
+	return nil
+}
+
+// AllowedOperators return list of Operator allowed for Where Argument (OperatorEq if Operators is empty)
+func (arg Argument) AllowedOperators() Operators {
+	if len(arg.Operators) == 0 {
+		return Operators{OperatorEq}
+	}
+	return arg.Operators
+}
+
+// Condition is single Where condition
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    interface{}
+}
+
+// Conditions is list of Condition joined by AND
+type Conditions []Condition
+
+// Filter is parsed request Where: list of Conditions joined by OR.
+// Empty Filter matches everything
+type Filter []Conditions
+
+// WhereKey return Where item key for field and operator
+func WhereKey(field string, op Operator) string {
+	if op == OperatorEq || len(op) == 0 {
+		return field
+	}
+	return field + "[" + string(op) + "]"
+}
+
+// ParseWhereKey split Where item key like "created[gt]" into field and operator
+func ParseWhereKey(key string) (field string, op Operator, ok bool) {
+	idx := strings.IndexByte(key, '[')
+	if idx < 0 {
+		return key, OperatorEq, len(key) > 0
+	}
+	if idx == 0 || !strings.HasSuffix(key, "]") {
+		return key, "", false
+	}
+	return key[:idx], Operator(key[idx+1 : len(key)-1]), true
+}
+
+// ParseFilter parse request Where into Filter. Keys are expected in form
+// returned by WhereKey (it is so after request validation)
+func ParseFilter(where []InterfaceMap) (Filter, error) {
+	filter := make(Filter, 0, len(where))
+	for _, item := range where {
+		item, err := normalizeWhere(item)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(item))
+		for key := range item {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		conds := make(Conditions, 0, len(keys))
+		for _, key := range keys {
+			field, op, _ := ParseWhereKey(key)
+			conds = append(conds, Condition{Field: field, Operator: op, Value: item[key]})
+		}
+		filter = append(filter, conds)
+	}
+	return filter, nil
+}
+
+// normalizeWhere expand nested operator objects into "field[op]" keys
+func normalizeWhere(item InterfaceMap) (InterfaceMap, error) {
+	result := make(InterfaceMap, len(item))
+	for key, val := range item {
+		field, op, ok := ParseWhereKey(key)
+		if !ok {
+			return nil, fmt.Errorf("invalid where key: %s", key)
+		}
+		var nested map[string]interface{}
+		switch v := val.(type) {
+		case map[string]interface{}:
+			nested = v
+		case InterfaceMap:
+			nested = v
+		case map[interface{}]interface{}:
+			nested = make(map[string]interface{}, len(v))
+			for k, vv := range v {
+				nested[fmt.Sprint(k)] = vv
+			}
+		}
+		if nested == nil {
+			if err := setWhere(result, WhereKey(field, op), val); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if op != OperatorEq || key != field {
+			return nil, fmt.Errorf("invalid where key: %s", key)
+		}
+		for nop, nval := range nested {
+			if err := setWhere(result, WhereKey(field, Operator(nop)), nval); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// setWhere set normalized condition key once, so "created[gt]" and
+// {"created":{"gt":...}} in one item are rejected instead of overwritten
+func setWhere(item InterfaceMap, key string, val interface{}) error {
+	if _, ok := item[key]; ok {
+		return fmt.Errorf("duplicate where condition: %s", key)
+	}
+	item[key] = val
+	return nil
+}
+
+func checkWhere(params *InterfaceMap, args Arguments, errPrefix string, types *ArgumentTypes) (e error) {
+	var err = &Error{}
+	item, e := normalizeWhere(*params)
+	if e != nil {
+		*err = DefaultErrors.Get(ErrInvalidWhereOperator)
+		err.Description = fmt.Sprintf("%s%s: %v", errPrefix, err.Description, e)
+		err.Details = []ErrorDetail{fieldDetail(errPrefix, "", e.Error())}
+		return err
+	}
+	fields := map[string]bool{}
+	for key := range item {
+		field, _, _ := ParseWhereKey(key)
+		fields[field] = true
+	}
+	for _, arg := range args {
+		if arg.Required && !arg.Disabled && !fields[arg.Name] {
+			*err = DefaultErrors.Get(ErrRequiredArgumentMissing)
+			err.Description = fmt.Sprintf("%s%s (%s)", errPrefix, err.Description, arg.Name)
+			err.Details = []ErrorDetail{fieldDetail(errPrefix, arg.Name, "required")}
+			return err
+		}
+	}
+	for key, val := range item {
+		field, op, _ := ParseWhereKey(key)
+		arg, gerr := args.Get(field)
+		if gerr != nil {
+			*err = DefaultErrors.Get(ErrUnknownArgument)
+			err.Description = fmt.Sprintf("%s%s (%s)", errPrefix, err.Description, field)
+			err.Details = []ErrorDetail{fieldDetail(errPrefix, field, "unknown")}
+			return err
+		}
+		if !arg.AllowedOperators().Contains(op) {
+			*err = DefaultErrors.Get(ErrInvalidWhereOperator)
+			err.Description = fmt.Sprintf("%s%s (%s, allowed: %s)", errPrefix, err.Description, key, arg.AllowedOperators())
+			detail := fieldDetail(errPrefix, key, "operator not allowed")
+			detail.Expected, detail.Actual = arg.AllowedOperators().String(), string(op)
+			err.Details = []ErrorDetail{detail}
+			return err
+		}
+		if val == nil {
+			if !arg.Nullable || (op != OperatorEq && op != OperatorNe) {
+				*err = DefaultErrors.Get(ErrInvalidArgumentValue)
+				err.Description = fmt.Sprintf("%s%s (%s): %v", errPrefix, err.Description, key, "null")
+				err.Details = []ErrorDetail{arg.valueDetail(errPrefix, key, "not nullable", nil)}
+				return err
+			}
+			(*params)[key] = nil
+			continue
+		}
+		t, list := arg.Type, arg.Multiple
+		switch op {
+		case OperatorIn, OperatorBetween:
+			list = true
+		case OperatorLike:
+			t, list = ArgumentTypeString, false
+		case OperatorNull:
+			t, list = ArgumentTypeBoolean, false
+		case OperatorLt, OperatorLe, OperatorGt, OperatorGe:
+			list = false
+		}
+		parsed, e := types.Parse(t, val, list)
+		if e != nil {
+			*err = DefaultErrors.Get(ErrInvalidArgumentValue)
+			err.Description = fmt.Sprintf("%s%s (%s): %v", errPrefix, err.Description, key, parsed)
+			err.Details = []ErrorDetail{Argument{Type: t, Multiple: list, Sensitive: arg.Sensitive}.valueDetail(errPrefix, key, "invalid type", val)}
+			err.Internal = e
+			return err
+		}
+		val = parsed
+		if op == OperatorBetween {
+			if n := listLen(val); n != 2 {
+				*err = DefaultErrors.Get(ErrInvalidArgumentValue)
+				err.Description = fmt.Sprintf("%s%s (%s): between requires 2 values, got %d", errPrefix, err.Description, key, n)
+				detail := fieldDetail(errPrefix, key, "invalid values count")
+				detail.Expected, detail.Actual = "2", fmt.Sprint(n)
+				err.Details = []ErrorDetail{detail}
+				return err
+			}
+		}
+		if len(arg.RegExp) > 0 && op != OperatorNull && op != OperatorLike {
+			ok, e := arg.matchItems(val, list)
+			if e != nil {
+				*err = DefaultErrors.Get(ErrInvalidArgumentRegExp)
+				err.Description = fmt.Sprintf("%s%s (%s)", errPrefix, err.Description, field)
+				return err
+			}
+			if !ok {
+				*err = DefaultErrors.Get(ErrArgumentRegExpMatchFails)
+				err.Description = fmt.Sprintf("%s%s (%s, regexp: %s)", errPrefix, err.Description, field, arg.RegExp)
+				detail := fieldDetail(errPrefix, key, "regexp mismatch")
+				detail.Expected, detail.Actual = arg.RegExp, arg.actual(val)
+				err.Details = []ErrorDetail{detail}
+				return err
+			}
+		}
+		(*params)[key] = val
+	}
+	// drop keys replaced by normalization (nested operator objects)
+	for key := range *params {
+		if _, ok := item[key]; !ok {
+			delete(*params, key)
+		}
+	}
+	return nil
+}
+
+func listLen(v interface{}) int {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return 1
+	}
+	return rv.Len()
+}
+
+// matchItems match each item of list v (or v itself) for Argument RegExp
+func (arg Argument) matchItems(v interface{}, list bool) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !list || rv.Kind() != reflect.Slice {
+		return arg.Match(v)
+	}
+	for idx := 0; idx < rv.Len(); idx++ {
+		if ok, err := arg.Match(rv.Index(idx).Interface()); !ok || err != nil {
+			return ok, err
+		}
+	}
+	return true, nil
+}
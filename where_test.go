@@ -0,0 +1,139 @@
+package sedoc
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWhereKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantField string
+		wantOp    Operator
+		wantOk    bool
+	}{
+		{"id", "id", OperatorEq, true},
+		{"created[gt]", "created", OperatorGt, true},
+		{"email[null]", "email", OperatorNull, true},
+		{"[gt]", "[gt]", "", false},
+		{"created[gt", "created[gt", "", false},
+		{"", "", OperatorEq, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			field, op, ok := ParseWhereKey(tt.key)
+			if field != tt.wantField || op != tt.wantOp || ok != tt.wantOk {
+				t.Errorf("ParseWhereKey() = %v, %v, %v, want %v, %v, %v", field, op, ok, tt.wantField, tt.wantOp, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	got, err := ParseFilter([]InterfaceMap{
+		{"name": "foo", "created[gt]": 1},
+		{"id": InterfaceMap{"in": []int{1, 2}}},
+	})
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	want := Filter{
+		{{Field: "created", Operator: OperatorGt, Value: 1}, {Field: "name", Operator: OperatorEq, Value: "foo"}},
+		{{Field: "id", Operator: OperatorIn, Value: []int{1, 2}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFilter() = %v, want %v", got, want)
+	}
+	if _, err = ParseFilter([]InterfaceMap{{"id[in]": map[string]interface{}{"eq": 1}}}); err == nil {
+		t.Errorf("ParseFilter() want error, but not")
+	}
+}
+
+func Test_checkWhere(t *testing.T) {
+	args := Arguments{
+		{Name: "id", Type: ArgumentTypeInteger, RegExp: "^\\d+$", Operators: Operators{OperatorEq, OperatorIn}},
+		{Name: "name", Type: ArgumentTypeString, Operators: Operators{OperatorEq, OperatorLike}},
+		{Name: "email", Type: ArgumentTypeEmail, Nullable: true, Operators: Operators{OperatorEq, OperatorNull}},
+		{Name: "created", Type: ArgumentTypeTime, Operators: ComparisonOperators},
+		{Name: "plain", Type: ArgumentTypeString},
+	}
+	created := time.Date(2018, 10, 16, 9, 58, 3, 0, time.UTC)
+	tests := []struct {
+		name     string
+		where    InterfaceMap
+		want     InterfaceMap
+		wantCode int
+	}{
+		{"eq", InterfaceMap{"id": "1", "plain": "a"}, InterfaceMap{"id": 1, "plain": "a"}, 0},
+		{"in", InterfaceMap{"id[in]": []interface{}{"1", 2.0}}, InterfaceMap{"id[in]": []int{1, 2}}, 0},
+		{"in regexp", InterfaceMap{"id[in]": []interface{}{1, -2}}, nil, ErrArgumentRegExpMatchFails},
+		{"like", InterfaceMap{"name[like]": "fo%"}, InterfaceMap{"name[like]": "fo%"}, 0},
+		{"null", InterfaceMap{"email[null]": "true"}, InterfaceMap{"email[null]": true}, 0},
+		{"eq null", InterfaceMap{"email": nil}, InterfaceMap{"email": nil}, 0},
+		{"gt null", InterfaceMap{"created[gt]": nil}, nil, ErrInvalidArgumentValue},
+		{"nested", InterfaceMap{"created": map[string]interface{}{"gt": "2018-10-16T09:58:03Z"}}, InterfaceMap{"created[gt]": created}, 0},
+		{"nested collision", InterfaceMap{"created[gt]": created, "created": map[string]interface{}{"gt": "2018-10-16T09:58:03Z"}}, nil, ErrInvalidWhereOperator},
+		{"eq collision", InterfaceMap{"id": "1", "id[eq]": "2"}, nil, ErrInvalidWhereOperator},
+		{"between", InterfaceMap{"created[between]": []interface{}{"2018-10-16T09:58:03Z", created}}, InterfaceMap{"created[between]": []time.Time{created, created}}, 0},
+		{"between one", InterfaceMap{"created[between]": []interface{}{created}}, nil, ErrInvalidArgumentValue},
+		{"not allowed", InterfaceMap{"plain[ne]": "a"}, nil, ErrInvalidWhereOperator},
+		{"unknown operator", InterfaceMap{"created[foo]": "a"}, nil, ErrInvalidWhereOperator},
+		{"invalid key", InterfaceMap{"[gt]": "a"}, nil, ErrInvalidWhereOperator},
+		{"unknown", InterfaceMap{"foo[gt]": "a"}, nil, ErrUnknownArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkWhere(&tt.where, args, "where[0]: ", nil)
+			code := 0
+			if err != nil {
+				code = err.(*Error).Code
+			}
+			if code != tt.wantCode {
+				t.Fatalf("checkWhere() error = %v, want code %d", err, tt.wantCode)
+			}
+			if code == 0 && !reflect.DeepEqual(tt.where, tt.want) {
+				t.Errorf("checkWhere() = %v, want %v", tt.where, tt.want)
+			}
+		})
+	}
+	required := Arguments{{Name: "id", Type: ArgumentTypeInteger, Required: true, Operators: Operators{OperatorIn}}}
+	if err := checkWhere(&InterfaceMap{"id[in]": []int{1}}, required, "", nil); err != nil {
+		t.Errorf("checkWhere() required error = %v", err)
+	}
+}
+
+func TestContext_Filter(t *testing.T) {
+	a := New()
+	var got Filter
+	a.AddCommand(Command{
+		Name: "user.get",
+		Where: Arguments{
+			{Name: "id", Type: ArgumentTypeInteger, Multiple: true},
+			{Name: "created", Type: ArgumentTypeTime, Operators: ComparisonOperators},
+		},
+		Handler: func(c Context) error {
+			var err error
+			got, err = c.Filter()
+			return err
+		},
+	})
+	u, _ := url.Parse("/?4-id=1&4-id=2&4-created[gt]=2018-10-16T09:58:03Z&4-created[lt]=2019-10-16T09:58:03Z")
+	resp := a.Execute(a.RequestFromURL(&Request{Command: "user.get"}, u))
+	if resp.Error != nil {
+		t.Fatalf("Execute() error = %v", resp.Error)
+	}
+	want := Filter{{
+		{Field: "created", Operator: OperatorGt, Value: time.Date(2018, 10, 16, 9, 58, 3, 0, time.UTC)},
+		{Field: "created", Operator: OperatorLt, Value: time.Date(2019, 10, 16, 9, 58, 3, 0, time.UTC)},
+		{Field: "id", Operator: OperatorEq, Value: []int{1, 2}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Context.Filter() = %v, want %v", got, want)
+	}
+	c := &context{api: a, req: &Request{Where: []InterfaceMap{{"[gt]": 1}}}}
+	if _, err := c.Filter(); err == nil {
+		t.Errorf("Context.Filter() want error, but not")
+	}
+}